@@ -0,0 +1,230 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// aggregatedPoint represents the merged point of series within time slot.
+type aggregatedPoint struct {
+	point      *Point         // merged point, timestamp is start of time slot
+	fields     map[string]int // field type/name => index of merged fields
+	timestamps []time.Time    // timestamp of point which merged field comes from(for first/last)
+}
+
+// aggregator merges points with identical namespace/metric/tags and time slot before encoding,
+// accessed by buffer process only.
+type aggregator struct {
+	series map[string]*aggregatedPoint // series key/time slot => merged point
+	keys   []string                    // series keys in arrival order
+}
+
+// newAggregator creates a points aggregator.
+func newAggregator() *aggregator {
+	return &aggregator{
+		series: make(map[string]*aggregatedPoint),
+	}
+}
+
+// add merges point into series of time slot, returns false if point cannot be merged(histogram bounds mismatch).
+func (a *aggregator) add(point *Point, slot time.Duration) bool {
+	slotStart := point.Timestamp().Truncate(slot)
+
+	var sb strings.Builder
+	writeSeriesKey(&sb, point)
+	sb.WriteByte(0)
+	sb.WriteString(strconv.FormatInt(slotStart.UnixMilli(), 10))
+	key := sb.String()
+
+	series, ok := a.series[key]
+	if !ok {
+		tags := make(map[string]string, len(point.tags))
+		for k, v := range point.tags {
+			tags[k] = v
+		}
+		series = &aggregatedPoint{
+			point: &Point{
+				namespace:  point.namespace,
+				metricName: point.metricName,
+				tags:       tags,
+				timestamp:  slotStart,
+			},
+			fields: make(map[string]int),
+		}
+		a.series[key] = series
+		a.keys = append(a.keys, key)
+	} else if !series.mergeable(point) {
+		return false
+	}
+	series.merge(point)
+	return true
+}
+
+// size returns the number of merged series.
+func (a *aggregator) size() int {
+	return len(a.keys)
+}
+
+// drain returns merged points in arrival order, then resets aggregator.
+func (a *aggregator) drain() []*Point {
+	if len(a.keys) == 0 {
+		return nil
+	}
+	points := make([]*Point, 0, len(a.keys))
+	for _, key := range a.keys {
+		points = append(points, a.series[key].point)
+	}
+	a.series = make(map[string]*aggregatedPoint)
+	a.keys = nil
+	return points
+}
+
+// mergeable checks if histogram of point can be merged into series(same buckets).
+func (p *aggregatedPoint) mergeable(point *Point) bool {
+	for _, f := range point.fields {
+		h, ok := f.(*Histogram)
+		if !ok {
+			continue
+		}
+		idx, ok := p.fields[fieldKey(f)]
+		if !ok {
+			continue
+		}
+		dst := p.point.fields[idx].(*Histogram)
+		if len(dst.values) != len(h.values) || !equalBounds(dst.bounds, h.bounds) {
+			return false
+		}
+	}
+	return true
+}
+
+// merge merges fields of point by field semantics.
+func (p *aggregatedPoint) merge(point *Point) {
+	timestamp := point.Timestamp()
+	for _, f := range point.fields {
+		key := fieldKey(f)
+		idx, ok := p.fields[key]
+		if !ok {
+			p.fields[key] = len(p.point.fields)
+			p.point.fields = append(p.point.fields, cloneField(f))
+			p.timestamps = append(p.timestamps, timestamp)
+			continue
+		}
+		switch dst := p.point.fields[idx].(type) {
+		case *Sum:
+			dst.v += f.(*Sum).v
+		case *Min:
+			dst.v = math.Min(dst.v, f.(*Min).v)
+		case *Max:
+			dst.v = math.Max(dst.v, f.(*Max).v)
+		case *First:
+			if timestamp.Before(p.timestamps[idx]) {
+				dst.v = f.(*First).v
+				p.timestamps[idx] = timestamp
+			}
+		case *Last:
+			if !timestamp.Before(p.timestamps[idx]) {
+				dst.v = f.(*Last).v
+				p.timestamps[idx] = timestamp
+			}
+		case *CumulativeSum:
+			// keep latest reading
+			if !timestamp.Before(p.timestamps[idx]) {
+				dst.v = f.(*CumulativeSum).v
+				p.timestamps[idx] = timestamp
+			}
+		case *Histogram:
+			src := f.(*Histogram)
+			dst.min = math.Min(dst.min, src.min)
+			dst.max = math.Max(dst.max, src.max)
+			dst.sum += src.sum
+			dst.count += src.count
+			for i := range dst.values {
+				dst.values[i] += src.values[i]
+			}
+		}
+	}
+}
+
+// fieldKey returns the key of field(type/name), histogram is the compound field without name.
+func fieldKey(f Field) string {
+	switch field := f.(type) {
+	case *Sum:
+		return "sum:" + field.name
+	case *Min:
+		return "min:" + field.name
+	case *Max:
+		return "max:" + field.name
+	case *First:
+		return "first:" + field.name
+	case *Last:
+		return "last:" + field.name
+	case *CumulativeSum:
+		return "cumulative:" + field.name
+	default:
+		return "histogram"
+	}
+}
+
+// cloneField returns a copy of field, merging does not modify the field of caller.
+func cloneField(f Field) Field {
+	switch field := f.(type) {
+	case *Sum:
+		cloned := *field
+		return &cloned
+	case *Min:
+		cloned := *field
+		return &cloned
+	case *Max:
+		cloned := *field
+		return &cloned
+	case *First:
+		cloned := *field
+		return &cloned
+	case *Last:
+		cloned := *field
+		return &cloned
+	case *CumulativeSum:
+		cloned := *field
+		return &cloned
+	case *Histogram:
+		cloned := *field
+		cloned.values = append([]float64(nil), field.values...)
+		cloned.bounds = append([]float64(nil), field.bounds...)
+		return &cloned
+	default:
+		return f
+	}
+}
+
+// equalBounds checks if histogram bounds are equal.
+func equalBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	httppkg "github.com/lindb/client_go/internal/http"
+)
+
+func TestAggregator_Merge(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	bounds := []float64{1, math.Inf(1)}
+	a := newAggregator()
+	p1 := NewPoint("cpu").AddTag("host", "h1").SetTimestamp(now.Add(2 * time.Second)).
+		AddField(NewSum("sum", 1)).
+		AddField(NewMin("min", 5)).
+		AddField(NewMax("max", 5)).
+		AddField(NewFirst("first", 5)).
+		AddField(NewLast("last", 5)).
+		AddField(NewCumulativeSum("cumulative", 5)).
+		AddField(NewHistogram(0.5, 0.5, 0.5, 1, []float64{1, 0}, bounds))
+	p2 := NewPoint("cpu").AddTag("host", "h1").SetTimestamp(now.Add(time.Second)).
+		AddField(NewSum("sum", 2)).
+		AddField(NewMin("min", 3)).
+		AddField(NewMax("max", 3)).
+		AddField(NewFirst("first", 3)).
+		AddField(NewLast("last", 3)).
+		AddField(NewCumulativeSum("cumulative", 3)).
+		AddField(NewHistogram(2, 2, 2, 1, []float64{0, 1}, bounds))
+	p3 := NewPoint("cpu").AddTag("host", "h1").SetTimestamp(now.Add(3 * time.Second)).
+		AddField(NewSum("sum", 3)).
+		AddField(NewMin("min", 7)).
+		AddField(NewMax("max", 7)).
+		AddField(NewFirst("first", 7)).
+		AddField(NewLast("last", 7)).
+		AddField(NewLast("new", 1))
+	assert.True(t, a.add(p1, 10*time.Second))
+	assert.True(t, a.add(p2, 10*time.Second))
+	assert.True(t, a.add(p3, 10*time.Second))
+	assert.Equal(t, 1, a.size())
+
+	points := a.drain()
+	assert.Len(t, points, 1)
+	assert.Equal(t, now.Truncate(10*time.Second), points[0].Timestamp())
+	assert.Equal(t, map[string]string{"host": "h1"}, points[0].Tags())
+	assert.Equal(t, []Field{
+		NewSum("sum", 6),
+		NewMin("min", 3),
+		NewMax("max", 7),
+		NewFirst("first", 3),
+		NewLast("last", 7),
+		NewCumulativeSum("cumulative", 5),
+		NewHistogram(0.5, 2, 2.5, 2, []float64{1, 1}, bounds),
+		NewLast("new", 1),
+	}, points[0].Fields())
+	// fields of caller not modified
+	assert.Equal(t, NewSum("sum", 1), p1.Fields()[0])
+	assert.Equal(t, NewHistogram(0.5, 0.5, 0.5, 1, []float64{1, 0}, bounds), p1.Fields()[6])
+
+	assert.Zero(t, a.size())
+	assert.Nil(t, a.drain())
+}
+
+func TestAggregator_Series(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	a := newAggregator()
+	a.add(NewPoint("cpu").SetTimestamp(now).AddField(NewSum("f", 1)), time.Second)
+	// different time slot
+	a.add(NewPoint("cpu").SetTimestamp(now.Add(time.Second)).AddField(NewSum("f", 1)), time.Second)
+	// different tags/metric/namespace
+	a.add(NewPoint("cpu").AddTag("host", "h1").SetTimestamp(now).AddField(NewSum("f", 1)), time.Second)
+	a.add(NewPoint("memory").SetTimestamp(now).AddField(NewSum("f", 1)), time.Second)
+	a.add(NewPoint("cpu").SetNamespace("ns").SetTimestamp(now).AddField(NewSum("f", 1)), time.Second)
+	// same series/slot
+	a.add(NewPoint("cpu").SetTimestamp(now.Add(500*time.Millisecond)).AddField(NewSum("f", 1)), time.Second)
+	assert.Equal(t, 5, a.size())
+
+	points := a.drain()
+	assert.Equal(t, []Field{NewSum("f", 2)}, points[0].Fields())
+	assert.Equal(t, "memory", points[3].MetricName())
+}
+
+func TestAggregator_HistogramMismatch(t *testing.T) {
+	a := newAggregator()
+	now := time.Now()
+	assert.True(t, a.add(NewPoint("cpu").SetTimestamp(now).
+		AddField(NewHistogram(1, 1, 1, 1, []float64{1, 0}, []float64{1, math.Inf(1)})), time.Second))
+	assert.False(t, a.add(NewPoint("cpu").SetTimestamp(now).
+		AddField(NewHistogram(1, 1, 1, 1, []float64{1, 0}, []float64{2, math.Inf(1)})), time.Second))
+	assert.False(t, a.add(NewPoint("cpu").SetTimestamp(now).
+		AddField(NewHistogram(1, 1, 1, 1, []float64{1, 0, 0}, []float64{1, math.Inf(1)})), time.Second))
+	assert.Equal(t, 1, a.size())
+}
+
+func TestWrite_Aggregate(t *testing.T) {
+	svr := newWriteServer(t, nil)
+
+	now := time.Now().Truncate(time.Minute)
+	write := func(opt *WriteOptions, n int) {
+		w := NewWrite(svr.URL, "test", opt.SetUseGZip(false).SetFlushInterval(time.Hour.Milliseconds()), httppkg.DefaultOptions())
+		for i := 0; i < n; i++ {
+			w.AddPoint(context.TODO(), NewPoint("cpu").AddTag("host", "h1").SetTimestamp(now).AddField(NewSum("load", 1)))
+		}
+		w.Close()
+	}
+	write(DefaultWriteOptions(), 1)
+	// duplicate series merged into single row
+	write(DefaultWriteOptions().SetAggregateInterval(time.Minute.Milliseconds()), 100)
+
+	requests := svr.requests()
+	assert.Len(t, requests, 2)
+	assert.Equal(t, len(requests[0].body), len(requests[1].body))
+
+	// batch is full by merged series
+	w := NewWrite(svr.URL, "test", DefaultWriteOptions().SetAggregateInterval(time.Minute.Milliseconds()).
+		SetBatchSize(2).SetFlushInterval(time.Hour.Milliseconds()), httppkg.DefaultOptions())
+	defer w.Close()
+	for i := 0; i < 10; i++ {
+		w.AddPoint(context.TODO(), NewPoint("cpu").AddTag("host", "h1").SetTimestamp(now).AddField(NewSum("load", 1)))
+	}
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddTag("host", "h2").SetTimestamp(now).AddField(NewSum("load", 1)))
+	assert.Eventually(t, func() bool { return len(svr.requests()) == 3 }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,113 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lindb/common/series"
+)
+
+// errCumulativeSumNotConverted represents cumulative sum is written without converting into delta.
+var errCumulativeSumNotConverted = errors.New("cumulative sum must be converted into delta by write client")
+
+// CumulativeSum represents cumulative(monotonic) sum field, e.g. Go runtime stats, /proc counters,
+// write client converts cumulative readings of series into delta Sum field.
+type CumulativeSum struct {
+	name string  // field name
+	v    float64 // cumulative value
+}
+
+// NewCumulativeSum creates a CumulativeSum field, the first reading of series(or after expired) is kept as baseline
+// and not written, decreased reading is treated as counter reset(restarted from 0).
+func NewCumulativeSum(name string, v float64) Field {
+	return &CumulativeSum{name: name, v: v}
+}
+
+// write returns error, cumulative sum must be converted into delta Sum field.
+func (c *CumulativeSum) write(_ *series.RowBuilder) error {
+	return errCumulativeSumNotConverted
+}
+
+// cumulativeState represents the last cumulative reading of series.
+type cumulativeState struct {
+	value     float64
+	timestamp time.Time // timestamp of point
+	lastSeen  time.Time // time of reading received
+}
+
+// cumulativeCache represents the per-series state cache for converting cumulative readings into deltas,
+// accessed by buffer process only.
+type cumulativeCache struct {
+	states     map[string]*cumulativeState // series key(namespace/metric/tags/field) => last reading
+	lastExpire time.Time
+}
+
+// newCumulativeCache creates a cumulative state cache.
+func newCumulativeCache(now time.Time) *cumulativeCache {
+	return &cumulativeCache{
+		states:     make(map[string]*cumulativeState),
+		lastExpire: now,
+	}
+}
+
+// delta returns the delta between reading and last reading of series, returns false if no delta written,
+// which is the first reading or out-of-order reading(older than last one).
+func (c *cumulativeCache) delta(key string, timestamp time.Time, value float64, now time.Time) (float64, bool) {
+	state, ok := c.states[key]
+	if !ok {
+		c.states[key] = &cumulativeState{value: value, timestamp: timestamp, lastSeen: now}
+		return 0, false
+	}
+	if timestamp.Before(state.timestamp) {
+		return 0, false
+	}
+	delta := value - state.value
+	if value < state.value {
+		// counter reset/restarted, count from 0
+		delta = value
+	}
+	state.value = value
+	state.timestamp = timestamp
+	state.lastSeen = now
+	return delta, true
+}
+
+// expire removes the series not seen within expiry, checks at most once per expiry.
+func (c *cumulativeCache) expire(now time.Time, expiry time.Duration) {
+	if now.Sub(c.lastExpire) < expiry {
+		return
+	}
+	c.lastExpire = now
+	for key, state := range c.states {
+		if now.Sub(state.lastSeen) >= expiry {
+			delete(c.states, key)
+		}
+	}
+}
+
+// cumulativeKey returns the series key of cumulative sum field of point.
+func cumulativeKey(point *Point, fieldName string) string {
+	var sb strings.Builder
+	writeSeriesKey(&sb, point)
+	sb.WriteByte(0)
+	sb.WriteString(fieldName)
+	return sb.String()
+}
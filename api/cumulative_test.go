@@ -0,0 +1,144 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/common/series"
+)
+
+func TestCumulativeCache_Delta(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	ts := func(sec int) time.Time { return now.Add(time.Duration(sec) * time.Second) }
+
+	type reading struct {
+		timestamp time.Time
+		value     float64
+		delta     float64
+		ok        bool
+	}
+	cases := []struct {
+		name     string
+		readings []reading
+	}{
+		{
+			name: "monotonic",
+			readings: []reading{
+				{timestamp: ts(0), value: 10},
+				{timestamp: ts(1), value: 15, delta: 5, ok: true},
+				{timestamp: ts(2), value: 15, delta: 0, ok: true},
+				{timestamp: ts(3), value: 20.5, delta: 5.5, ok: true},
+			},
+		},
+		{
+			name: "counter reset",
+			readings: []reading{
+				{timestamp: ts(0), value: 100},
+				{timestamp: ts(1), value: 3, delta: 3, ok: true},
+				{timestamp: ts(2), value: 10, delta: 7, ok: true},
+			},
+		},
+		{
+			name: "out-of-order reading dropped",
+			readings: []reading{
+				{timestamp: ts(0), value: 10},
+				{timestamp: ts(2), value: 20, delta: 10, ok: true},
+				{timestamp: ts(1), value: 15},
+				{timestamp: ts(3), value: 25, delta: 5, ok: true},
+			},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCumulativeCache(time.Now())
+			for _, r := range tt.readings {
+				delta, ok := c.delta("key", r.timestamp, r.value, now)
+				assert.Equal(t, r.ok, ok)
+				assert.Equal(t, r.delta, delta)
+			}
+		})
+	}
+}
+
+func TestCumulativeCache_Expire(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := newCumulativeCache(time.Now())
+	c.lastExpire = now
+	c.delta("k1", now, 1, now)
+	c.delta("k2", now, 1, now.Add(30*time.Second))
+
+	// not checked within expiry
+	c.expire(now.Add(30*time.Second), time.Minute)
+	assert.Len(t, c.states, 2)
+
+	c.expire(now.Add(time.Minute), time.Minute)
+	assert.Len(t, c.states, 1)
+	assert.Contains(t, c.states, "k2")
+
+	// expired series restarts from baseline
+	_, ok := c.delta("k1", now.Add(time.Minute), 5, now.Add(time.Minute))
+	assert.False(t, ok)
+	delta, ok := c.delta("k1", now.Add(2*time.Minute), 8, now.Add(2*time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, delta)
+}
+
+func TestCumulativeKey(t *testing.T) {
+	p1 := NewPoint("cpu").AddTag("host", "h1").AddTag("ip", "1.1.1.1")
+	p2 := NewPoint("cpu").AddTag("ip", "1.1.1.1").AddTag("host", "h1")
+	assert.Equal(t, cumulativeKey(p1, "load"), cumulativeKey(p2, "load"))
+	assert.NotEqual(t, cumulativeKey(p1, "load"), cumulativeKey(p1, "idle"))
+	assert.NotEqual(t, cumulativeKey(p1, "load"), cumulativeKey(p1.SetNamespace("ns"), "load"))
+	assert.NotEqual(t, cumulativeKey(NewPoint("cpu"), "load"), cumulativeKey(NewPoint("memory"), "load"))
+}
+
+func TestCumulativeSum_write(t *testing.T) {
+	assert.Equal(t, errCumulativeSumNotConverted, NewCumulativeSum("f", 1).write(series.CreateRowBuilder()))
+}
+
+func TestWrite_CumulativeSum(t *testing.T) {
+	w := &write{
+		builder:    series.CreateRowBuilder(),
+		buf:        &bytes.Buffer{},
+		cumulative: newCumulativeCache(time.Now()),
+		nowFn:      time.Now,
+	}
+	w.writeOptions.Store(DefaultWriteOptions())
+	now := time.Now()
+
+	// baseline only, point skipped
+	assert.NoError(t, w.batchPoint(NewPoint("proc").SetTimestamp(now).AddField(NewCumulativeSum("ctx_switches", 10))))
+	assert.Zero(t, w.batchedSize)
+	assert.Zero(t, w.buf.Len())
+
+	// baseline with other fields, point written without cumulative field
+	assert.NoError(t, w.batchPoint(NewPoint("proc").SetTimestamp(now).
+		AddField(NewCumulativeSum("forks", 10)).
+		AddField(NewLast("threads", 2))))
+	assert.Equal(t, 1, w.batchedSize)
+
+	// delta written
+	assert.NoError(t, w.batchPoint(NewPoint("proc").SetTimestamp(now.Add(time.Second)).AddField(NewCumulativeSum("ctx_switches", 15))))
+	assert.Equal(t, 2, w.batchedSize)
+}
@@ -25,7 +25,9 @@ import (
 	"github.com/lindb/common/models"
 	"github.com/lindb/common/pkg/encoding"
 
+	"github.com/lindb/client_go/internal/discovery"
 	httppkg "github.com/lindb/client_go/internal/http"
+	"github.com/lindb/client_go/trace"
 )
 
 // For testing
@@ -47,15 +49,22 @@ type DataQuery interface {
 
 // dataQuery implements DataQuery interface.
 type dataQuery struct {
-	endpoint string
+	resolver discovery.Resolver
 	client   *http.Client
+	tracer   trace.Tracer
 }
 
 // NewDataQuery creates a data query client.
 func NewDataQuery(endpoint string, httpOptions *httppkg.Options) DataQuery {
+	return NewDataQueryWithResolver(discovery.NewStaticResolver(endpoint), httpOptions)
+}
+
+// NewDataQueryWithResolver creates a data query client, which sends request to broker picked by resolver.
+func NewDataQueryWithResolver(resolver discovery.Resolver, httpOptions *httppkg.Options) DataQuery {
 	return &dataQuery{
-		endpoint: fmt.Sprintf("%s/api/v1/exec", endpoint),
+		resolver: resolver,
 		client:   httpOptions.HTTPClient(),
+		tracer:   httpOptions.Tracer(),
 	}
 }
 
@@ -63,7 +72,7 @@ func NewDataQuery(endpoint string, httpOptions *httppkg.Options) DataQuery {
 // LinQL ref: https://lindb.io/guide/lin-ql.html#metric-query
 // Example: select heap_objects from lindb.runtime.mem where 'role' in ('Broker') group by node
 func (q *dataQuery) DataQuery(ctx context.Context, database, ql string) (*models.ResultSet, error) {
-	resp, err := q.sendRequest(ctx, database, ql)
+	resp, err := q.sendRequest(ctx, trace.SpanDataQuery, database, ql)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +88,7 @@ func (q *dataQuery) DataQuery(ctx context.Context, database, ql string) (*models
 // LinQL ref: https://lindb.io/guide/lin-ql.html#metric-meta-query
 // Example: show fields from lindb.runtime.mem
 func (q *dataQuery) MetadataQuery(ctx context.Context, database, ql string) (*models.Metadata, error) {
-	resp, err := q.sendRequest(ctx, database, ql)
+	resp, err := q.sendRequest(ctx, trace.SpanMetadataQuery, database, ql)
 	if err != nil {
 		return nil, err
 	}
@@ -91,8 +100,22 @@ func (q *dataQuery) MetadataQuery(ctx context.Context, database, ql string) (*mo
 	return rs, nil
 }
 
-// sendRequest sends query request.
-func (q *dataQuery) sendRequest(ctx context.Context, database, ql string) ([]byte, error) {
+// sendRequest sends query request, creates span around request if tracer set.
+func (q *dataQuery) sendRequest(ctx context.Context, spanName, database, ql string) (resp []byte, err error) {
+	endpoint := q.resolver.Endpoint()
+	ctx, span := trace.StartSpan(ctx, q.tracer, spanName)
+	span.SetAttributes(
+		trace.String(trace.AttrDatabase, database),
+		trace.String(trace.AttrStatement, ql),
+		trace.String(trace.AttrEndpoint, endpoint),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
 	param := struct {
 		Database string `json:"db"`
 		QL       string `json:"sql"`
@@ -100,5 +123,5 @@ func (q *dataQuery) sendRequest(ctx context.Context, database, ql string) ([]byt
 		Database: database,
 		QL:       ql,
 	}
-	return doPutFn(ctx, q.client, q.endpoint, encoding.JSONMarshal(&param))
+	return doPutFn(ctx, q.client, fmt.Sprintf("%s/api/v1/exec", endpoint), encoding.JSONMarshal(&param))
 }
@@ -147,3 +147,49 @@ func (h *Histogram) write(builder *series.RowBuilder) error {
 	}
 	return builder.AddCompoundFieldData(h.values, h.bounds)
 }
+
+// FieldType represents the type of simple field, used for mapping values of other formats(JSON, line protocol etc.).
+type FieldType string
+
+const (
+	// FieldTypeSum represents delta sum field.
+	FieldTypeSum FieldType = "sum"
+	// FieldTypeMin represents min field.
+	FieldTypeMin FieldType = "min"
+	// FieldTypeMax represents max field.
+	FieldTypeMax FieldType = "max"
+	// FieldTypeFirst represents first field.
+	FieldTypeFirst FieldType = "first"
+	// FieldTypeLast represents last field.
+	FieldTypeLast FieldType = "last"
+	// FieldTypeCumulativeSum represents cumulative sum field, converted into delta by write client.
+	FieldTypeCumulativeSum FieldType = "cumulative"
+)
+
+// Valid checks if field type is supported.
+func (t FieldType) Valid() bool {
+	switch t {
+	case FieldTypeSum, FieldTypeMin, FieldTypeMax, FieldTypeFirst, FieldTypeLast, FieldTypeCumulativeSum:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewField creates field by field type, Last field created if field type is unknown.
+func (t FieldType) NewField(name string, v float64) Field {
+	switch t {
+	case FieldTypeSum:
+		return NewSum(name, v)
+	case FieldTypeMin:
+		return NewMin(name, v)
+	case FieldTypeMax:
+		return NewMax(name, v)
+	case FieldTypeFirst:
+		return NewFirst(name, v)
+	case FieldTypeCumulativeSum:
+		return NewCumulativeSum(name, v)
+	default:
+		return NewLast(name, v)
+	}
+}
@@ -50,3 +50,16 @@ func TestHistogramField(t *testing.T) {
 	histogram = NewHistogram(-1.0, 10.0, 100.0, 20.0, []float64{1, 2, 3}, []float64{1, 2, math.Inf(0)})
 	assert.Error(t, histogram.write(builder))
 }
+
+func TestFieldType(t *testing.T) {
+	assert.Equal(t, NewSum("f", 1), FieldTypeSum.NewField("f", 1))
+	assert.Equal(t, NewMin("f", 1), FieldTypeMin.NewField("f", 1))
+	assert.Equal(t, NewMax("f", 1), FieldTypeMax.NewField("f", 1))
+	assert.Equal(t, NewFirst("f", 1), FieldTypeFirst.NewField("f", 1))
+	assert.Equal(t, NewLast("f", 1), FieldTypeLast.NewField("f", 1))
+	assert.Equal(t, NewCumulativeSum("f", 1), FieldTypeCumulativeSum.NewField("f", 1))
+	assert.Equal(t, NewLast("f", 1), FieldType("avg").NewField("f", 1))
+
+	assert.True(t, FieldTypeSum.Valid())
+	assert.False(t, FieldType("avg").Valid())
+}
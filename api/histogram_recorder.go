@@ -0,0 +1,154 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// DefaultHistogramBuckets represents LinDB's default bucket layout(upper bounds of latency in ms),
+// 20 exponential buckets from 1ms to 5s.
+var DefaultHistogramBuckets = ExponentialBucketsRange(1, 5_000, 20)
+
+// LinearBuckets returns count bucket upper bounds, starting at start, each width apart,
+// panics if count < 1, start < 0 or width <= 0.
+func LinearBuckets(start, width float64, count int) []float64 {
+	if count < 1 || start < 0 || width <= 0 {
+		panic(fmt.Sprintf("lindb: invalid linear buckets, start: %v, width: %v, count: %d", start, width, count))
+	}
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start + float64(i)*width
+	}
+	return bounds
+}
+
+// ExponentialBuckets returns count bucket upper bounds, starting at start, each factor times the previous one,
+// panics if count < 1, start <= 0 or factor <= 1.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	if count < 1 || start <= 0 || factor <= 1 {
+		panic(fmt.Sprintf("lindb: invalid exponential buckets, start: %v, factor: %v, count: %d", start, factor, count))
+	}
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start
+		start *= factor
+	}
+	return bounds
+}
+
+// ExponentialBucketsRange returns count exponential bucket upper bounds from min to max,
+// panics if count < 2, min <= 0 or max <= min.
+func ExponentialBucketsRange(min, max float64, count int) []float64 {
+	if count < 2 || min <= 0 || max <= min {
+		panic(fmt.Sprintf("lindb: invalid exponential buckets range, min: %v, max: %v, count: %d", min, max, count))
+	}
+	bounds := ExponentialBuckets(min, math.Pow(max/min, 1/float64(count-1)), count)
+	bounds[count-1] = max // avoid float error
+	return bounds
+}
+
+// HistogramRecorder records raw observations into buckets, concurrent safe.
+// Bucket i contains observations in (bounds[i-1], bounds[i]], the last bucket is +Inf.
+// Negative observations are dropped, because negative bucket is not supported by LinDB.
+type HistogramRecorder struct {
+	bounds []float64 // bucket upper bounds, last one is +Inf
+
+	values               []float64
+	min, max, sum, count float64
+	mutex                sync.Mutex
+}
+
+// NewHistogramRecorder creates a HistogramRecorder with bucket upper bounds(DefaultHistogramBuckets if empty),
+// +Inf bucket is appended if needed, panics if bounds are negative, NaN or not strictly increasing.
+func NewHistogramRecorder(bounds ...float64) *HistogramRecorder {
+	if len(bounds) == 0 {
+		bounds = DefaultHistogramBuckets
+	}
+	b := make([]float64, 0, len(bounds)+1)
+	for i, bound := range bounds {
+		if math.IsNaN(bound) || bound < 0 || (i > 0 && bound <= bounds[i-1]) {
+			panic(fmt.Sprintf("lindb: histogram bounds must be non-negative and strictly increasing, got %v", bounds))
+		}
+		b = append(b, bound)
+	}
+	if !math.IsInf(b[len(b)-1], 1) {
+		b = append(b, math.Inf(1))
+	}
+	return &HistogramRecorder{
+		bounds: b,
+		values: make([]float64, len(b)),
+	}
+}
+
+// Bounds returns bucket upper bounds(including +Inf).
+func (r *HistogramRecorder) Bounds() []float64 {
+	return append([]float64(nil), r.bounds...)
+}
+
+// Observe records an observation, NaN or negative value is ignored.
+func (r *HistogramRecorder) Observe(v float64) {
+	r.ObserveN(v, 1)
+}
+
+// ObserveN records an observation weighted as n observations(e.g. 1/sample rate of sampled observation),
+// NaN or negative value and non-positive weight are ignored.
+func (r *HistogramRecorder) ObserveN(v, n float64) {
+	if math.IsNaN(v) || v < 0 || math.IsNaN(n) || math.IsInf(n, 0) || n <= 0 {
+		return
+	}
+	idx := sort.SearchFloat64s(r.bounds, v)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.count == 0 || v < r.min {
+		r.min = v
+	}
+	if r.count == 0 || v > r.max {
+		r.max = v
+	}
+	r.sum += v * n
+	r.count += n
+	r.values[idx] += n
+}
+
+// Count returns the number of observations since last snapshot.
+func (r *HistogramRecorder) Count() float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.count
+}
+
+// Snapshot returns the Histogram field of observations since last snapshot, then resets recorder,
+// returns nil if no observation.
+func (r *HistogramRecorder) Snapshot() Field {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.count == 0 {
+		return nil
+	}
+	field := NewHistogram(r.min, r.max, r.sum, r.count, r.values, r.Bounds())
+	r.values = make([]float64, len(r.bounds))
+	r.min, r.max, r.sum, r.count = 0, 0, 0, 0
+	return field
+}
@@ -0,0 +1,154 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuckets(t *testing.T) {
+	assert.Equal(t, []float64{1, 3, 5}, LinearBuckets(1, 2, 3))
+	assert.Equal(t, []float64{1, 2, 4, 8}, ExponentialBuckets(1, 2, 4))
+	assert.Equal(t, []float64{1, 10, 100}, ExponentialBucketsRange(1, 100, 3))
+	assert.Len(t, DefaultHistogramBuckets, 20)
+	assert.Equal(t, 1.0, DefaultHistogramBuckets[0])
+	assert.Equal(t, 5_000.0, DefaultHistogramBuckets[19])
+
+	for _, fn := range []func(){
+		func() { LinearBuckets(1, 2, 0) },
+		func() { LinearBuckets(-1, 2, 1) },
+		func() { LinearBuckets(1, 0, 1) },
+		func() { ExponentialBuckets(1, 2, 0) },
+		func() { ExponentialBuckets(0, 2, 1) },
+		func() { ExponentialBuckets(1, 1, 1) },
+		func() { ExponentialBucketsRange(1, 10, 1) },
+		func() { ExponentialBucketsRange(0, 10, 2) },
+		func() { ExponentialBucketsRange(10, 10, 2) },
+	} {
+		assert.Panics(t, fn)
+	}
+}
+
+func TestNewHistogramRecorder(t *testing.T) {
+	assert.Equal(t, append(DefaultHistogramBuckets, math.Inf(1)), NewHistogramRecorder().Bounds())
+	assert.Equal(t, []float64{1, math.Inf(1)}, NewHistogramRecorder(1, math.Inf(1)).Bounds())
+
+	for _, bounds := range [][]float64{
+		{-1, 1},
+		{2, 1},
+		{1, 1},
+		{math.NaN()},
+	} {
+		bounds := bounds
+		assert.Panics(t, func() { NewHistogramRecorder(bounds...) })
+	}
+}
+
+func TestHistogramRecorder_Snapshot(t *testing.T) {
+	cases := []struct {
+		name         string
+		bounds       []float64
+		observations []float64
+		expect       Field
+	}{
+		{
+			name:   "no observation",
+			bounds: []float64{1, 2},
+			expect: nil,
+		},
+		{
+			name:         "bucket upper bound inclusive",
+			bounds:       []float64{1, 2},
+			observations: []float64{0.5, 1, 1.5, 2, 3},
+			expect:       NewHistogram(0.5, 3, 8, 5, []float64{2, 2, 1}, []float64{1, 2, math.Inf(1)}),
+		},
+		{
+			name:         "negative ignored",
+			bounds:       []float64{1, 2},
+			observations: []float64{-5, 0.5, -0.1},
+			expect:       NewHistogram(0.5, 0.5, 0.5, 1, []float64{1, 0, 0}, []float64{1, 2, math.Inf(1)}),
+		},
+		{
+			name:         "negative only",
+			bounds:       []float64{1, 2},
+			observations: []float64{-5},
+			expect:       nil,
+		},
+		{
+			name:         "NaN ignored",
+			bounds:       LinearBuckets(0, 10, 2),
+			observations: []float64{math.NaN(), 0, 15},
+			expect:       NewHistogram(0, 15, 15, 2, []float64{1, 0, 1}, []float64{0, 10, math.Inf(1)}),
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewHistogramRecorder(tt.bounds...)
+			for _, v := range tt.observations {
+				r.Observe(v)
+			}
+			assert.Equal(t, tt.expect, r.Snapshot())
+			// reset after snapshot
+			assert.Equal(t, 0.0, r.Count())
+			assert.Nil(t, r.Snapshot())
+		})
+	}
+}
+
+func TestHistogramRecorder_ObserveN(t *testing.T) {
+	r := NewHistogramRecorder(1, 2)
+	r.ObserveN(0.5, 4)
+	r.ObserveN(1.5, 2)
+	r.ObserveN(3, 0)
+	r.ObserveN(3, -1)
+	r.ObserveN(3, math.NaN())
+	r.ObserveN(3, math.Inf(1))
+	r.ObserveN(math.NaN(), 1)
+	r.ObserveN(-1, 1)
+	assert.Equal(t, NewHistogram(0.5, 1.5, 5, 6, []float64{4, 2, 0}, []float64{1, 2, math.Inf(1)}), r.Snapshot())
+}
+
+func TestHistogramRecorder_Concurrent(t *testing.T) {
+	r := NewHistogramRecorder(ExponentialBuckets(1, 2, 10)...)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= 100; j++ {
+				r.Observe(float64(j))
+			}
+		}()
+	}
+	wg.Wait()
+	h := r.Snapshot().(*Histogram)
+	assert.Equal(t, 1000.0, h.count)
+	assert.Equal(t, 50500.0, h.sum)
+	assert.Equal(t, 1.0, h.min)
+	assert.Equal(t, 100.0, h.max)
+	total := 0.0
+	for _, v := range h.values {
+		total += v
+	}
+	assert.Equal(t, 1000.0, total)
+}
@@ -0,0 +1,308 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LineValueType represents the value type of field in InfluxDB line protocol.
+type LineValueType int
+
+const (
+	// LineValueFloat represents float value, e.g. 1.5.
+	LineValueFloat LineValueType = iota + 1
+	// LineValueInteger represents signed integer value, e.g. 1i.
+	LineValueInteger
+	// LineValueUnsigned represents unsigned integer value, e.g. 1u.
+	LineValueUnsigned
+	// LineValueBoolean represents boolean value(converted into 1/0), e.g. true.
+	LineValueBoolean
+)
+
+// LineProtocolError represents the error of parsing InfluxDB line protocol.
+type LineProtocolError struct {
+	Line    int // line number of first invalid line, starts from 1
+	Err     error
+	Invalid int // number of invalid lines
+}
+
+// Error returns the error message with line number.
+func (e *LineProtocolError) Error() string {
+	if e.Invalid > 1 {
+		return fmt.Sprintf("line %d: %s(%d invalid lines)", e.Line, e.Err, e.Invalid)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *LineProtocolError) Unwrap() error {
+	return e.Err
+}
+
+// LineProtocolParser represents the parser which converts InfluxDB line protocol into points.
+// Measurement is mapped to metric name, tag set to tags, numeric/boolean fields to fields(string fields skipped).
+// Field type is picked by field name mapping, then value type mapping, default Last.
+// Parser must not be modified after used by write client.
+type LineProtocolParser struct {
+	precision  time.Duration
+	fieldTypes map[string]FieldType        // field name => field type
+	valueTypes map[LineValueType]FieldType // value type => field type
+}
+
+// NewLineProtocolParser creates a LineProtocolParser with nanosecond precision.
+func NewLineProtocolParser() *LineProtocolParser {
+	return &LineProtocolParser{
+		precision:  time.Nanosecond,
+		fieldTypes: make(map[string]FieldType),
+		valueTypes: make(map[LineValueType]FieldType),
+	}
+}
+
+// SetPrecision sets precision of timestamp, e.g. time.Millisecond.
+func (p *LineProtocolParser) SetPrecision(precision time.Duration) *LineProtocolParser {
+	p.precision = precision
+	return p
+}
+
+// Precision returns precision of timestamp.
+func (p *LineProtocolParser) Precision() time.Duration {
+	return p.precision
+}
+
+// SetFieldType sets field type of field name.
+func (p *LineProtocolParser) SetFieldType(field string, fieldType FieldType) *LineProtocolParser {
+	p.fieldTypes[field] = fieldType
+	return p
+}
+
+// SetValueFieldType sets field type of value type, used if field name not mapped.
+func (p *LineProtocolParser) SetValueFieldType(valueType LineValueType, fieldType FieldType) *LineProtocolParser {
+	p.valueTypes[valueType] = fieldType
+	return p
+}
+
+// Parse parses lines from reader, fn is invoked for each point, invalid lines are skipped,
+// returns *LineProtocolError of first invalid line or error of reading.
+func (p *LineProtocolParser) Parse(r io.Reader, fn func(point *Point)) error {
+	var parseErr *LineProtocolError
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		point, lineErr := p.ParseLine(data)
+		switch {
+		case lineErr != nil && parseErr == nil:
+			parseErr = &LineProtocolError{Line: line, Err: lineErr, Invalid: 1}
+		case lineErr != nil:
+			parseErr.Invalid++
+		case point != nil:
+			fn(point)
+		}
+		if err != nil {
+			// EOF
+			break
+		}
+	}
+	if parseErr != nil {
+		return parseErr
+	}
+	return nil
+}
+
+// WriteTo parses lines from reader, then adds points into buffer of write client,
+// invalid lines are skipped, returns *LineProtocolError of first invalid line or error of reading.
+func (p *LineProtocolParser) WriteTo(ctx context.Context, w Write, r io.Reader) error {
+	err := p.Parse(r, func(point *Point) {
+		w.AddPoint(ctx, point)
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// ParseLine parses single line into point, returns nil if line is blank or comment.
+func (p *LineProtocolParser) ParseLine(line []byte) (*Point, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] == '#' {
+		return nil, nil
+	}
+	keyEnd := indexUnescaped(line, ' ', false)
+	if keyEnd < 0 {
+		return nil, errors.New("missing field set")
+	}
+	key := line[:keyEnd]
+	rest := bytes.TrimLeft(line[keyEnd:], " ")
+	fieldsEnd := indexUnescaped(rest, ' ', true)
+	if fieldsEnd < 0 {
+		fieldsEnd = len(rest)
+	}
+	fieldSet := rest[:fieldsEnd]
+	timestamp := bytes.TrimSpace(rest[fieldsEnd:])
+
+	// measurement/tag set
+	parts := splitUnescaped(key, ',', false)
+	measurement := unescape(parts[0])
+	if measurement == "" {
+		return nil, errors.New("missing measurement")
+	}
+	point := NewPoint(measurement)
+	for _, tag := range parts[1:] {
+		idx := indexUnescaped(tag, '=', false)
+		if idx <= 0 || idx == len(tag)-1 {
+			return nil, fmt.Errorf("invalid tag %q", tag)
+		}
+		point.AddTag(unescape(tag[:idx]), unescape(tag[idx+1:]))
+	}
+
+	// field set
+	if len(fieldSet) == 0 {
+		return nil, errors.New("missing field set")
+	}
+	for _, field := range splitUnescaped(fieldSet, ',', true) {
+		idx := indexUnescaped(field, '=', false)
+		if idx <= 0 || idx == len(field)-1 {
+			return nil, fmt.Errorf("invalid field %q", field)
+		}
+		name := unescape(field[:idx])
+		value, valueType, err := parseLineValue(field[idx+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of field %q: %w", name, err)
+		}
+		if valueType == 0 {
+			// string field not supported
+			continue
+		}
+		point.AddField(p.fieldType(name, valueType).NewField(name, value))
+	}
+	if len(point.Fields()) == 0 {
+		return nil, errors.New("no numeric/boolean field")
+	}
+
+	// timestamp
+	if len(timestamp) > 0 {
+		ts, err := strconv.ParseInt(string(timestamp), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q", timestamp)
+		}
+		precision := p.precision
+		if precision <= 0 {
+			precision = time.Nanosecond
+		}
+		point.SetTimestamp(time.Unix(0, ts*int64(precision)))
+	}
+	return point, nil
+}
+
+// fieldType returns field type by field name, then value type.
+func (p *LineProtocolParser) fieldType(name string, valueType LineValueType) FieldType {
+	if fieldType, ok := p.fieldTypes[name]; ok {
+		return fieldType
+	}
+	if fieldType, ok := p.valueTypes[valueType]; ok {
+		return fieldType
+	}
+	return FieldTypeLast
+}
+
+// parseLineValue parses field value, returns value type 0 for string value.
+func parseLineValue(v []byte) (float64, LineValueType, error) {
+	s := string(v)
+	switch {
+	case s[0] == '"':
+		if len(s) < 2 || s[len(s)-1] != '"' {
+			return 0, 0, fmt.Errorf("unterminated string %s", s)
+		}
+		return 0, 0, nil
+	case s == "t" || s == "T" || s == "true" || s == "True" || s == "TRUE":
+		return 1, LineValueBoolean, nil
+	case s == "f" || s == "F" || s == "false" || s == "False" || s == "FALSE":
+		return 0, LineValueBoolean, nil
+	case strings.HasSuffix(s, "i"):
+		i, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+		return float64(i), LineValueInteger, err
+	case strings.HasSuffix(s, "u"):
+		u, err := strconv.ParseUint(s[:len(s)-1], 10, 64)
+		return float64(u), LineValueUnsigned, err
+	default:
+		f, err := strconv.ParseFloat(s, 64)
+		if err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			err = fmt.Errorf("non-finite float %s", s)
+		}
+		return f, LineValueFloat, err
+	}
+}
+
+// indexUnescaped returns the index of first unescaped sep(outside double quotes if quoted), -1 if not found.
+func indexUnescaped(b []byte, sep byte, quoted bool) int {
+	inQuote := false
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '\\':
+			i++ // skip escaped byte
+		case quoted && b[i] == '"':
+			inQuote = !inQuote
+		case b[i] == sep && !inQuote:
+			return i
+		}
+	}
+	return -1
+}
+
+// splitUnescaped splits by unescaped sep(outside double quotes if quoted).
+func splitUnescaped(b []byte, sep byte, quoted bool) [][]byte {
+	var parts [][]byte
+	for {
+		idx := indexUnescaped(b, sep, quoted)
+		if idx < 0 {
+			return append(parts, b)
+		}
+		parts = append(parts, b[:idx])
+		b = b[idx+1:]
+	}
+}
+
+// unescape removes backslash of escaped comma/equal sign/space/double quote/backslash.
+func unescape(b []byte) string {
+	if bytes.IndexByte(b, '\\') < 0 {
+		return string(b)
+	}
+	var sb strings.Builder
+	for i := 0; i < len(b); i++ {
+		if b[i] == '\\' && i+1 < len(b) {
+			switch b[i+1] {
+			case ',', '=', ' ', '"', '\\':
+				i++
+			}
+		}
+		sb.WriteByte(b[i])
+	}
+	return sb.String()
+}
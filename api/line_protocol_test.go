@@ -0,0 +1,187 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	httppkg "github.com/lindb/client_go/internal/http"
+)
+
+func TestLineProtocolParser_ParseLine(t *testing.T) {
+	p := NewLineProtocolParser()
+	cases := []struct {
+		name   string
+		line   string
+		metric string
+		tags   map[string]string
+		fields []Field
+		ts     time.Time
+	}{
+		{
+			name:   "full line",
+			line:   "cpu,host=h1,region=us-west load=1.5,count=3i,total=4u,up=true,state=\"running, ok\" 1700000000000000000\n",
+			metric: "cpu",
+			tags:   map[string]string{"host": "h1", "region": "us-west"},
+			fields: []Field{NewLast("load", 1.5), NewLast("count", 3), NewLast("total", 4), NewLast("up", 1)},
+			ts:     time.Unix(1_700_000_000, 0),
+		},
+		{
+			name:   "escaped",
+			line:   `my\ metric,my\,tag=a\=b\ c field\=x=-1e3,down=F`,
+			metric: "my metric",
+			tags:   map[string]string{"my,tag": "a=b c"},
+			fields: []Field{NewLast("field=x", -1000), NewLast("down", 0)},
+		},
+		{
+			name:   "quoted string with escaped quote and space",
+			line:   `log msg="a \"b c",n=1 1`,
+			metric: "log",
+			fields: []Field{NewLast("n", 1)},
+			ts:     time.Unix(0, 1),
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			point, err := p.ParseLine([]byte(tt.line))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.metric, point.MetricName())
+			assert.Equal(t, tt.tags, point.Tags())
+			assert.Equal(t, tt.fields, point.Fields())
+			if !tt.ts.IsZero() {
+				assert.Equal(t, tt.ts, point.Timestamp())
+			}
+		})
+	}
+
+	for _, line := range []string{"", "  \n", "# comment"} {
+		point, err := p.ParseLine([]byte(line))
+		assert.NoError(t, err)
+		assert.Nil(t, point)
+	}
+}
+
+func TestLineProtocolParser_ParseLine_Error(t *testing.T) {
+	p := NewLineProtocolParser()
+	cases := []struct {
+		line string
+		err  string
+	}{
+		{line: "cpu", err: "missing field set"},
+		{line: ",host=h1 load=1", err: "missing measurement"},
+		{line: "cpu,host load=1", err: "invalid tag"},
+		{line: "cpu,host= load=1", err: "invalid tag"},
+		{line: "cpu load", err: "invalid field"},
+		{line: "cpu load=", err: "invalid field"},
+		{line: "cpu load=abc", err: `invalid value of field "load"`},
+		{line: "cpu load=NaN", err: "non-finite float"},
+		{line: "cpu load=1.5i", err: `invalid value of field "load"`},
+		{line: "cpu load=-1u", err: `invalid value of field "load"`},
+		{line: `cpu msg="abc`, err: "unterminated string"},
+		{line: `cpu msg="abc"`, err: "no numeric/boolean field"},
+		{line: "cpu load=1 abc", err: "invalid timestamp"},
+	}
+	for _, tt := range cases {
+		point, err := p.ParseLine([]byte(tt.line))
+		assert.Nil(t, point, tt.line)
+		assert.ErrorContains(t, err, tt.err, tt.line)
+	}
+}
+
+func TestLineProtocolParser_Mapping(t *testing.T) {
+	p := NewLineProtocolParser().
+		SetPrecision(time.Millisecond).
+		SetFieldType("requests", FieldTypeCumulativeSum).
+		SetValueFieldType(LineValueInteger, FieldTypeSum).
+		SetValueFieldType(LineValueFloat, FieldTypeMax)
+	assert.Equal(t, time.Millisecond, p.Precision())
+	point, err := p.ParseLine([]byte("http requests=10i,errors=1i,latency=12.5,up=t 1700000000123"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Field{
+		NewCumulativeSum("requests", 10),
+		NewSum("errors", 1),
+		NewMax("latency", 12.5),
+		NewLast("up", 1),
+	}, point.Fields())
+	assert.Equal(t, time.UnixMilli(1_700_000_000_123), point.Timestamp())
+
+	// non-positive precision treated as nanosecond
+	point, err = NewLineProtocolParser().SetPrecision(0).ParseLine([]byte("cpu load=1 10"))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(0, 10), point.Timestamp())
+}
+
+func TestLineProtocolParser_Parse(t *testing.T) {
+	p := NewLineProtocolParser()
+	var points []*Point
+	err := p.Parse(strings.NewReader("cpu load=1\n# comment\ncpu\n\nmemory used=2\ncpu load\n"), func(point *Point) {
+		points = append(points, point)
+	})
+	assert.Len(t, points, 2)
+	var lineErr *LineProtocolError
+	assert.True(t, errors.As(err, &lineErr))
+	assert.Equal(t, 3, lineErr.Line)
+	assert.Equal(t, 2, lineErr.Invalid)
+	assert.Equal(t, "line 3: missing field set(2 invalid lines)", err.Error())
+	assert.Equal(t, "line 1: missing field set", (&LineProtocolError{Line: 1, Err: lineErr.Err, Invalid: 1}).Error())
+
+	// last line without newline
+	points = nil
+	assert.NoError(t, p.Parse(strings.NewReader("cpu load=1\ncpu load=2"), func(point *Point) {
+		points = append(points, point)
+	}))
+	assert.Len(t, points, 2)
+
+	// read failure
+	assert.EqualError(t, p.Parse(iotest.ErrReader(io.ErrUnexpectedEOF), func(_ *Point) {}), io.ErrUnexpectedEOF.Error())
+}
+
+func TestLineProtocolParser_WriteTo(t *testing.T) {
+	svr := newWriteServer(t, nil)
+
+	p := NewLineProtocolParser().SetPrecision(time.Second)
+	w := NewWrite(svr.URL, "test", DefaultWriteOptions().SetBatchSize(2), httppkg.DefaultOptions())
+	assert.NoError(t, p.WriteTo(context.TODO(), w, strings.NewReader("cpu load=1 1700000000\ncpu load=2 1700000001\n")))
+	assert.Eventually(t, func() bool { return len(svr.requests()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Error(t, p.WriteTo(context.TODO(), w, strings.NewReader("cpu\n")))
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	assert.Equal(t, context.Canceled, p.WriteTo(ctx, w, strings.NewReader("cpu load=1\n")))
+	w.Close()
+}
+
+func TestWrite_AddLineProtocol(t *testing.T) {
+	svr := newWriteServer(t, nil)
+
+	w := NewWrite(svr.URL, "test", DefaultWriteOptions().SetBatchSize(2), httppkg.DefaultOptions())
+	assert.NoError(t, w.AddLineProtocol(context.TODO(), strings.NewReader("cpu load=1\ncpu load=2\n")))
+	assert.Eventually(t, func() bool { return len(svr.requests()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	var lineErr *LineProtocolError
+	assert.ErrorAs(t, w.AddLineProtocol(context.TODO(), strings.NewReader("cpu\n")), &lineErr)
+	w.Close()
+}
@@ -18,6 +18,7 @@
 package api
 
 import (
+	"sort"
 	"strings"
 	"time"
 )
@@ -101,3 +102,28 @@ func (p *Point) Valid() bool {
 	}
 	return true
 }
+
+// SeriesKey returns the unique key of series(namespace/metric/tags sorted by key).
+func SeriesKey(namespace, metricName string, tags map[string]string) string {
+	var sb strings.Builder
+	writeSeriesKey(&sb, &Point{namespace: namespace, metricName: metricName, tags: tags})
+	return sb.String()
+}
+
+// writeSeriesKey writes the series key(namespace/metric/sorted tags) of point.
+func writeSeriesKey(sb *strings.Builder, point *Point) {
+	sb.WriteString(point.namespace)
+	sb.WriteByte(0)
+	sb.WriteString(point.metricName)
+	keys := make([]string, 0, len(point.tags))
+	for key := range point.tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		sb.WriteByte(0)
+		sb.WriteString(key)
+		sb.WriteByte('=')
+		sb.WriteString(point.tags[key])
+	}
+}
@@ -41,3 +41,11 @@ func TestPoint(t *testing.T) {
 	assert.False(t, NewPoint("").Valid())
 	assert.False(t, NewPoint("xx").Valid())
 }
+
+func TestSeriesKey(t *testing.T) {
+	key := SeriesKey("ns", "cpu", map[string]string{"k2": "v2", "k1": "v1"})
+	assert.Equal(t, "ns\x00cpu\x00k1=v1\x00k2=v2", key)
+	assert.Equal(t, key, SeriesKey("ns", "cpu", map[string]string{"k1": "v1", "k2": "v2"}))
+	assert.NotEqual(t, key, SeriesKey("", "cpu", map[string]string{"k1": "v1", "k2": "v2"}))
+	assert.Equal(t, "\x00cpu", SeriesKey("", "cpu", nil))
+}
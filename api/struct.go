@@ -0,0 +1,290 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// structTagKey represents the struct tag key of point encoding, e.g. `lindb:"host,tag"`, `lindb:"load,sum"`.
+const structTagKey = "lindb"
+
+// MetricNamer represents the struct which provides metric name of point, required by PointFromStruct.
+type MetricNamer interface {
+	// MetricName returns metric name.
+	MetricName() string
+}
+
+// encodeKind represents how struct field is encoded into point.
+type encodeKind int
+
+const (
+	encodeTag encodeKind = iota + 1
+	encodeNamespace
+	encodeTimestamp
+	encodeSum
+	encodeMin
+	encodeMax
+	encodeFirst
+	encodeLast
+	encodeCumulative
+)
+
+// encodeKinds represents the supported options of struct tag.
+var encodeKinds = map[string]encodeKind{
+	"tag":        encodeTag,
+	"namespace":  encodeNamespace,
+	"timestamp":  encodeTimestamp,
+	"sum":        encodeSum,
+	"min":        encodeMin,
+	"max":        encodeMax,
+	"first":      encodeFirst,
+	"last":       encodeLast,
+	"cumulative": encodeCumulative,
+}
+
+// encodeFieldTypes represents the field type of encode kind, Last field created for kind not mapped.
+var encodeFieldTypes = map[encodeKind]FieldType{
+	encodeSum:        FieldTypeSum,
+	encodeMin:        FieldTypeMin,
+	encodeMax:        FieldTypeMax,
+	encodeFirst:      FieldTypeFirst,
+	encodeLast:       FieldTypeLast,
+	encodeCumulative: FieldTypeCumulativeSum,
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+
+	// encoders caches the encoder of struct type, reflect.Type => *structEncoder.
+	encoders sync.Map
+)
+
+// fieldEncoder represents the encoder of struct field.
+type fieldEncoder struct {
+	index []int // index sequence of (embedded) field
+	name  string
+	kind  encodeKind
+}
+
+// structEncoder represents the encoder of struct type.
+type structEncoder struct {
+	fields []fieldEncoder
+	err    error
+}
+
+// PointFromStruct encodes struct(or pointer to struct) into point by struct tags,
+// metric name is provided by MetricNamer, fields are encoded by `lindb:"<name>,<kind>"`, e.g.
+//
+//	type CPU struct {
+//		Host      string    `lindb:"host,tag"`
+//		Load      float64   `lindb:"load,sum"`
+//		Usage     *float64  `lindb:"usage,last"`
+//		Timestamp time.Time `lindb:",timestamp"`
+//	}
+//
+// Kinds: tag(string/bool/int/uint/float), namespace(string), timestamp(time.Time),
+// sum/min/max/first/last/cumulative(int/uint/float). Name defaults to struct field name, "-" skips field,
+// nil pointer field is skipped, embedded structs are encoded recursively.
+func PointFromStruct(v any) (*Point, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil, errors.New("cannot encode nil into point")
+	}
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errors.New("cannot encode nil into point")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot encode %s into point, expect struct", rv.Type())
+	}
+	encoder := structEncoderOf(rv.Type())
+	if encoder.err != nil {
+		return nil, encoder.err
+	}
+	namer, ok := v.(MetricNamer)
+	if !ok && rv.CanAddr() {
+		// method with pointer receiver
+		namer, ok = rv.Addr().Interface().(MetricNamer)
+	}
+	if !ok {
+		return nil, fmt.Errorf("cannot encode %s into point, metric name required(implement MetricNamer)", rv.Type())
+	}
+	point := NewPoint(namer.MetricName())
+	for i := range encoder.fields {
+		f := &encoder.fields[i]
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok {
+			continue
+		}
+		switch f.kind {
+		case encodeTag:
+			point.AddTag(f.name, formatTagValue(fv))
+		case encodeNamespace:
+			point.SetNamespace(fv.String())
+		case encodeTimestamp:
+			if ts := fv.Interface().(time.Time); !ts.IsZero() {
+				point.SetTimestamp(ts)
+			}
+		default:
+			point.AddField(newField(f.kind, f.name, numberValue(fv)))
+		}
+	}
+	return point, nil
+}
+
+// structEncoderOf returns the (cached) encoder of struct type.
+func structEncoderOf(t reflect.Type) *structEncoder {
+	if encoder, ok := encoders.Load(t); ok {
+		return encoder.(*structEncoder)
+	}
+	encoder := &structEncoder{}
+	encoder.err = encoder.build(t, nil)
+	encoders.Store(t, encoder)
+	return encoder
+}
+
+// build collects field encoders of struct type(including embedded structs).
+func (e *structEncoder) build(t reflect.Type, index []int) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+		tag, tagged := sf.Tag.Lookup(structTagKey)
+		if tag == "-" {
+			continue
+		}
+		if !tagged {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if sf.Anonymous && ft.Kind() == reflect.Struct {
+				if err := e.build(ft, fieldIndex); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			return fmt.Errorf("cannot encode %s.%s: unexported field", t, sf.Name)
+		}
+		name, option, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		kind, ok := encodeKinds[option]
+		if !ok {
+			return fmt.Errorf("cannot encode %s.%s: unknown kind %q of struct tag", t, sf.Name, option)
+		}
+		if !supportedType(kind, sf.Type) {
+			return fmt.Errorf("cannot encode %s.%s: unsupported type %s for %s", t, sf.Name, sf.Type, option)
+		}
+		e.fields = append(e.fields, fieldEncoder{index: fieldIndex, name: name, kind: kind})
+	}
+	return nil
+}
+
+// supportedType checks if field type(or pointer to it) is supported by encode kind.
+func supportedType(kind encodeKind, t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch kind {
+	case encodeTag:
+		return isNumber(t.Kind()) || t.Kind() == reflect.String || t.Kind() == reflect.Bool
+	case encodeNamespace:
+		return t.Kind() == reflect.String
+	case encodeTimestamp:
+		return t == timeType
+	default:
+		return isNumber(t.Kind())
+	}
+}
+
+// isNumber checks if kind is int/uint/float.
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// fieldByIndex returns the field value by index sequence, returns false if nil pointer on the path.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// formatTagValue formats string/bool/number value as tag value.
+func formatTagValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	default:
+		return strconv.FormatInt(v.Int(), 10)
+	}
+}
+
+// numberValue returns int/uint/float value as float64.
+func numberValue(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	default:
+		return float64(v.Int())
+	}
+}
+
+// newField creates simple field by field type of encode kind.
+func newField(kind encodeKind, name string, v float64) Field {
+	return encodeFieldTypes[kind].NewField(name, v)
+}
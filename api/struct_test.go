@@ -0,0 +1,180 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hostInfo struct {
+	Host string `lindb:"host,tag"`
+	Zone string `lindb:"zone,tag"`
+}
+
+type procInfo struct {
+	PID int `lindb:"pid,tag"`
+}
+
+type cpuStat struct {
+	hostInfo
+	*procInfo
+	Namespace string    `lindb:",namespace"`
+	Timestamp time.Time `lindb:"ts,timestamp"`
+	Core      uint8     `lindb:"core,tag"`
+	Virtual   bool      `lindb:"virtual,tag"`
+	Load      float64   `lindb:"load,sum"`
+	Min       int32     `lindb:"min,min"`
+	Max       uint64    `lindb:"max,max"`
+	First     float32   `lindb:"first,first"`
+	Usage     *float64  `lindb:"usage,last"`
+	Idle      *float64  `lindb:"idle,last"`
+	Switches  int64     `lindb:"switches,cumulative"`
+	Temp      float64   `lindb:",last"`
+	Ignored   float64   `lindb:"-"`
+	Untagged  float64
+}
+
+func (c *cpuStat) MetricName() string {
+	return "cpu"
+}
+
+type memStat struct {
+	Used float64 `lindb:"used,last"`
+}
+
+func (memStat) MetricName() string {
+	return "memory"
+}
+
+func TestPointFromStruct(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	usage := 0.8
+	p, err := PointFromStruct(&cpuStat{
+		hostInfo:  hostInfo{Host: "h1", Zone: "z1"},
+		procInfo:  &procInfo{PID: 100},
+		Namespace: "ns",
+		Timestamp: now,
+		Core:      2,
+		Virtual:   true,
+		Load:      1.5,
+		Min:       -1,
+		Max:       10,
+		First:     0.5,
+		Usage:     &usage,
+		Switches:  1000,
+		Temp:      60,
+		Ignored:   1,
+		Untagged:  1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu", p.MetricName())
+	assert.Equal(t, "ns", p.Namespace())
+	assert.Equal(t, now, p.Timestamp())
+	assert.Equal(t, map[string]string{"host": "h1", "zone": "z1", "pid": "100", "core": "2", "virtual": "true"}, p.Tags())
+	assert.Equal(t, []Field{
+		NewSum("load", 1.5),
+		NewMin("min", -1),
+		NewMax("max", 10),
+		NewFirst("first", 0.5),
+		NewLast("usage", 0.8),
+		NewCumulativeSum("switches", 1000),
+		NewLast("Temp", 60),
+	}, p.Fields())
+
+	// nil embedded pointer skipped, zero timestamp keeps default
+	p, err = PointFromStruct(&cpuStat{Load: 1})
+	assert.NoError(t, err)
+	assert.NotContains(t, p.Tags(), "pid")
+	assert.False(t, p.Timestamp().IsZero())
+
+	// value receiver
+	p, err = PointFromStruct(memStat{Used: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, "memory", p.MetricName())
+	assert.Equal(t, []Field{NewLast("used", 10)}, p.Fields())
+
+	// encoder cached
+	encoder, ok := encoders.Load(reflect.TypeOf(cpuStat{}))
+	assert.True(t, ok)
+	assert.Same(t, encoder, structEncoderOf(reflect.TypeOf(cpuStat{})))
+}
+
+type noName struct {
+	Used float64 `lindb:"used,last"`
+}
+
+type unknownKind struct {
+	memStat
+	Used float64 `lindb:"used,avg"`
+}
+
+type unsupportedField struct {
+	memStat
+	Used string `lindb:"used,sum"`
+}
+
+type unsupportedTag struct {
+	memStat
+	Host []string `lindb:"host,tag"`
+}
+
+type unsupportedTimestamp struct {
+	memStat
+	Timestamp int64 `lindb:"ts,timestamp"`
+}
+
+type unexportedField struct {
+	memStat
+	used float64 `lindb:"used,last"` //nolint:unused
+}
+
+type nestedError struct {
+	unknownKind
+}
+
+func TestPointFromStruct_Error(t *testing.T) {
+	var nilStat *memStat
+	cases := []struct {
+		name string
+		v    any
+		err  string
+	}{
+		{name: "nil", v: nil, err: "cannot encode"},
+		{name: "nil pointer", v: nilStat, err: "cannot encode nil into point"},
+		{name: "not struct", v: 1, err: "expect struct"},
+		{name: "metric name required", v: noName{}, err: "metric name required"},
+		{name: "unknown kind", v: unknownKind{}, err: `unknown kind "avg"`},
+		{name: "unsupported field type", v: unsupportedField{}, err: "unsupported type string for sum"},
+		{name: "unsupported tag type", v: unsupportedTag{}, err: "unsupported type []string for tag"},
+		{name: "unsupported timestamp type", v: unsupportedTimestamp{}, err: "unsupported type int64 for timestamp"},
+		{name: "unexported field", v: unexportedField{}, err: "unexported field"},
+		{name: "embedded struct", v: nestedError{}, err: `unknown kind "avg"`},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := PointFromStruct(tt.v)
+			assert.Nil(t, p)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
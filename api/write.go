@@ -25,6 +25,7 @@ import (
 	"io"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/klauspost/compress/gzip"
@@ -32,82 +33,212 @@ import (
 	"github.com/lindb/common/series"
 
 	"github.com/lindb/client_go/internal"
+	"github.com/lindb/client_go/internal/discovery"
 	httppkg "github.com/lindb/client_go/internal/http"
+	"github.com/lindb/client_go/internal/ratelimit"
+	"github.com/lindb/client_go/trace"
 )
 
 const (
 	// ContentTypeFlat represents flat buffer content type.
 	ContentTypeFlat = "application/flatbuffer"
+	// maxRetryAfter represents the maximum delay honoured from Retry-After of broker,
+	// avoids blocking write client too long.
+	maxRetryAfter = time.Minute
 )
 
+// ErrThrottled represents the write request is throttled by broker(429 Too Many Requests etc.).
+var ErrThrottled = errors.New("write throttled by broker")
+
+// ThrottledError represents the error of write request throttled by broker,
+// further requests sent to the endpoint are delayed by RetryAfter, errors.Is(err, ErrThrottled) returns true.
+type ThrottledError struct {
+	Endpoint   string        // broker endpoint
+	StatusCode int           // status code of response
+	RetryAfter time.Duration // delay requested by broker
+	Message    string        // response body
+}
+
+// Error returns the error message.
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("write throttled by broker %s(status %d), retry after %s: %s",
+		e.Endpoint, e.StatusCode, e.RetryAfter, e.Message)
+}
+
+// Unwrap returns ErrThrottled.
+func (e *ThrottledError) Unwrap() error {
+	return ErrThrottled
+}
+
 var (
-	errTooManyRetryRequests = errors.New("too many retry requests, drop current request")
-	errTooManyRetry         = errors.New("max retry attempt")
+	errRateLimited           = errors.New("write rate limit exceeded, drop current request")
+	errTooManyRetryRequests  = errors.New("too many retry requests, drop current request")
+	errTooManyRetry          = errors.New("max retry attempt")
+	errWriteClosed           = errors.New("write client closed")
+	errTooManyPausedRequests = errors.New("too many requests held while write client paused, drop current request")
 )
 
+// bufferedPoint represents the point added into buffer with context of caller.
+type bufferedPoint struct {
+	ctx   context.Context
+	point *Point
+}
+
+// batch represents the batched write data.
+type batch struct {
+	ctx    context.Context // context of first point batched(without cancellation), parent of write span
+	data   []byte          // marshaled points
+	points int             // number of points
+}
+
 // retryReq represents request need to retry.
 type retryReq struct {
-	data     io.Reader
+	ctx      context.Context
+	data     []byte // request body(compressed if it needs)
+	gzipped  bool   // whether request body is compressed
+	points   int
 	attempts int
 }
 
+// WriteStats represents the statistics of write client.
+type WriteStats struct {
+	// Number of points delayed or dropped by rate limit.
+	ThrottledPoints int64
+	// Number of points dropped by rate limit.
+	DroppedPoints int64
+}
+
 // Write represents write client for writing time series data asynchronously.
 type Write interface {
-	// AddPoint adds a time series point into buffer.
+	// AddPoint adds a time series point into buffer, waits until buffer available or ctx done,
+	// span carried by ctx(of first point in batch) is the parent of write request span.
 	AddPoint(ctx context.Context, point *Point)
+	// AddLineProtocol parses InfluxDB line protocol from reader by default parser, then adds points into buffer,
+	// invalid lines are skipped, returns *LineProtocolError of first invalid line or error of reading.
+	AddLineProtocol(ctx context.Context, r io.Reader) error
 	// Errors watches error in background goroutine.
 	Errors() <-chan error
 	// Close closes write client, before close try to send pending points.
 	Close()
 }
 
+// WriteUpdater represents the write client whose write options can be updated at runtime,
+// implemented by write client created by NewWrite/NewWriteWithResolver, e.g. w.(api.WriteUpdater).
+type WriteUpdater interface {
+	// Update applies new write options to running write client without losing buffered points,
+	// modify is invoked with a copy of current options, returns error if new options are invalid.
+	Update(modify func(opt *WriteOptions)) error
+}
+
+// WritePauser represents the write client whose sending can be paused/resumed,
+// implemented by write client created by NewWrite/NewWriteWithResolver, e.g. w.(api.WritePauser).
+type WritePauser interface {
+	// Pause pauses sending write requests, points are still accepted and batched,
+	// batched requests are held in order(up to pause buffer limit) until resumed.
+	Pause()
+	// Resume resumes sending write requests, held requests are sent in order.
+	Resume()
+	// Paused returns whether write client is paused.
+	Paused() bool
+}
+
+// WriteStatsReporter represents the write client which reports statistics,
+// implemented by write client created by NewWrite/NewWriteWithResolver, e.g. w.(api.WriteStatsReporter).
+type WriteStatsReporter interface {
+	// Stats returns the statistics of write client.
+	Stats() WriteStats
+}
+
 // write implements Write interface.
 type write struct {
-	endpoint     string
+	resolver     discovery.Resolver
 	database     string
-	writeOptions *WriteOptions
+	writeOptions atomic.Pointer[WriteOptions]
 	client       *http.Client
+	tracer       trace.Tracer
 
-	bufferCh    chan *Point
-	sendCh      chan []byte
+	bufferCh    chan bufferedPoint
+	sendCh      chan *batch
 	errCh       chan error
+	updateCh    chan struct{}
+	resumeCh    chan struct{}
 	stopBatchCh chan struct{}
 	stopSendCh  chan struct{}
 	doneCh      chan struct{}
 
+	throttledUntil map[string]time.Time // endpoint => time of sending allowed, accessed by send process only
+	cumulative     *cumulativeCache     // accessed by buffer process only
+	batchCtx       context.Context      // context of first point in current batch, accessed by buffer process only
+	aggregator     *aggregator          // accessed by buffer process only
+
 	builder     *series.RowBuilder
 	buf         *bytes.Buffer
 	gzipBuf     *bytes.Buffer
 	gzipWriter  *gzip.Writer
 	batchedSize int
 
+	nowFn   func() time.Time                                 // current time, replaced in test
+	sleepFn func(d time.Duration, stop <-chan struct{}) bool // interruptible sleep, replaced in test
+
+	throttledPoints atomic.Int64
+	droppedPoints   atomic.Int64
+
+	paused atomic.Bool
 	closed bool
 	mutex  sync.Mutex
 }
 
-// NewWrite creates an asynchronously write client.
+// NewWrite creates an asynchronously write client, invalid write options are replaced by default values,
+// use WriteOptions.Validate to check write options.
 func NewWrite(endpoint, database string, writeOptions *WriteOptions, httpOptions *httppkg.Options) Write {
+	return NewWriteWithResolver(discovery.NewStaticResolver(endpoint), database, writeOptions, httpOptions)
+}
+
+// NewWriteWithResolver creates an asynchronously write client, which sends data to broker picked by resolver.
+// Write options are copied, later modification does not affect created write client,
+// invalid write options are replaced by default values.
+func NewWriteWithResolver(resolver discovery.Resolver, database string,
+	writeOptions *WriteOptions, httpOptions *httppkg.Options,
+) Write {
+	w := newWrite(resolver, database, writeOptions.withDefaults(), httpOptions)
+	w.start()
+	return w
+}
+
+// newWrite creates an asynchronously write client with valid write options, processes not started.
+func newWrite(resolver discovery.Resolver, database string,
+	writeOptions *WriteOptions, httpOptions *httppkg.Options,
+) *write {
 	w := &write{
-		endpoint:     fmt.Sprintf("%s/api/v1/write?db=%s", endpoint, database),
-		database:     database,
-		client:       httpOptions.HTTPClient(),
-		writeOptions: writeOptions,
-		bufferCh:     make(chan *Point, writeOptions.BatchSize()+1),
-		sendCh:       make(chan []byte),
-		errCh:        make(chan error),
-		stopBatchCh:  make(chan struct{}),
-		stopSendCh:   make(chan struct{}),
-		doneCh:       make(chan struct{}),
-		builder:      series.CreateRowBuilder(),
-		buf:          &bytes.Buffer{},
-	}
-	if writeOptions.UseGZip() {
-		w.gzipBuf = &bytes.Buffer{}
-		w.gzipWriter = gzip.NewWriter(w.gzipBuf)
+		resolver:    resolver,
+		database:    database,
+		client:      httpOptions.HTTPClient(),
+		tracer:      httpOptions.Tracer(),
+		bufferCh:    make(chan bufferedPoint, writeOptions.BatchSize()+1),
+		sendCh:      make(chan *batch),
+		errCh:       make(chan error),
+		updateCh:    make(chan struct{}, 1),
+		resumeCh:    make(chan struct{}, 1),
+		stopBatchCh: make(chan struct{}),
+		stopSendCh:  make(chan struct{}),
+		doneCh:      make(chan struct{}),
+		builder:     series.CreateRowBuilder(),
+		buf:         &bytes.Buffer{},
+		nowFn:       time.Now,
+		sleepFn:     sleep,
+
+		throttledUntil: make(map[string]time.Time),
+		aggregator:     newAggregator(),
 	}
+	w.writeOptions.Store(writeOptions)
+	return w
+}
+
+// start starts buffer/send processes.
+func (w *write) start() {
+	w.cumulative = newCumulativeCache(w.nowFn())
 	go w.bufferProc() // process point->data([]byte)
 	go w.sendProc()   // send data to server
-	return w
 }
 
 // AddPoint adds a time series point into buffer.
@@ -117,16 +248,91 @@ func (w *write) AddPoint(ctx context.Context, point *Point) {
 	}
 	select {
 	case <-ctx.Done():
-	case w.bufferCh <- point:
+	case w.bufferCh <- bufferedPoint{ctx: ctx, point: point}:
 	}
 }
 
+// AddLineProtocol parses InfluxDB line protocol from reader by default parser(nanosecond precision, Last fields),
+// then adds points into buffer, use LineProtocolParser.WriteTo for custom precision/field mapping.
+func (w *write) AddLineProtocol(ctx context.Context, r io.Reader) error {
+	return NewLineProtocolParser().WriteTo(ctx, w, r)
+}
+
 // Errors watches error in background goroutine.
 func (w *write) Errors() <-chan error {
 	return w.errCh
 }
 
-// Close closes write client, before close try to send pending points.
+// Update applies new write options to running write client without losing buffered points,
+// modify is invoked with a copy of current options(invoked again if options updated concurrently),
+// returns error if new options are invalid.
+// Batch size/flush interval take effect in buffer process immediately(flushes if batch is full),
+// default tags take effect for points batched after update, retry/gzip settings take effect for next request.
+func (w *write) Update(modify func(opt *WriteOptions)) error {
+	for {
+		current := w.options()
+		opt := current.Clone()
+		modify(opt)
+		if err := opt.Validate(); err != nil {
+			return err
+		}
+		w.mutex.Lock()
+		if w.closed {
+			w.mutex.Unlock()
+			return errWriteClosed
+		}
+		swapped := w.writeOptions.CompareAndSwap(current, opt)
+		w.mutex.Unlock()
+		if !swapped {
+			// options updated concurrently, modify latest options
+			continue
+		}
+		// notify buffer process to apply new options
+		select {
+		case w.updateCh <- struct{}{}:
+		default:
+			// pending notification, new options will be loaded
+		}
+		return nil
+	}
+}
+
+// Pause pauses sending write requests, points are still accepted and batched,
+// batched requests are held in order(up to pause buffer limit) until resumed.
+func (w *write) Pause() {
+	w.paused.Store(true)
+}
+
+// Resume resumes sending write requests, held requests are sent in order.
+func (w *write) Resume() {
+	if w.paused.CompareAndSwap(true, false) {
+		// notify send process to send held requests
+		select {
+		case w.resumeCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Paused returns whether write client is paused.
+func (w *write) Paused() bool {
+	return w.paused.Load()
+}
+
+// Stats returns the statistics of write client.
+func (w *write) Stats() WriteStats {
+	return WriteStats{
+		ThrottledPoints: w.throttledPoints.Load(),
+		DroppedPoints:   w.droppedPoints.Load(),
+	}
+}
+
+// options returns current write options.
+func (w *write) options() *WriteOptions {
+	return w.writeOptions.Load()
+}
+
+// Close closes write client, before close try to send pending points(including requests held while paused).
 func (w *write) Close() {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
@@ -150,8 +356,10 @@ func (w *write) Close() {
 
 // bufferProc consumes time series point from buffer chan, marshals point then put data into send buffer.
 func (w *write) bufferProc() {
-	batchSize := w.writeOptions.BatchSize()
-	ticker := time.NewTicker(time.Duration(w.writeOptions.flushInterval) * time.Millisecond)
+	opt := w.options()
+	batchSize := opt.BatchSize()
+	flushInterval := opt.FlushInterval()
+	ticker := time.NewTicker(time.Duration(flushInterval) * time.Millisecond)
 
 	defer func() {
 		ticker.Stop()
@@ -160,21 +368,33 @@ func (w *write) bufferProc() {
 
 	for {
 		select {
-		case point := <-w.bufferCh:
-			if err := w.batchPoint(point); err != nil {
+		case p := <-w.bufferCh:
+			if err := w.bufferPoint(p); err != nil {
 				w.emitErr(err)
 				continue
 			}
 			// check batch buffer is full
-			if w.batchedSize >= batchSize {
+			if w.batchedSize+w.aggregator.size() >= batchSize {
 				w.flushBuffer()
 			}
 		case <-ticker.C:
 			w.flushBuffer()
+			w.cumulative.expire(w.nowFn(), time.Duration(w.options().CumulativeExpiry())*time.Millisecond)
+		case <-w.updateCh:
+			opt = w.options()
+			batchSize = opt.BatchSize()
+			if opt.FlushInterval() != flushInterval {
+				flushInterval = opt.FlushInterval()
+				ticker.Reset(time.Duration(flushInterval) * time.Millisecond)
+			}
+			// check batch buffer is full after batch size changed
+			if w.batchedSize+w.aggregator.size() >= batchSize {
+				w.flushBuffer()
+			}
 		case <-w.stopBatchCh:
 			// try to batch pending points
-			for point := range w.bufferCh {
-				if err := w.batchPoint(point); err != nil {
+			for p := range w.bufferCh {
+				if err := w.bufferPoint(p); err != nil {
 					w.emitErr(err)
 				}
 			}
@@ -184,12 +404,40 @@ func (w *write) bufferProc() {
 	}
 }
 
-// flushBuffer flushes buffer data, put data into send chan, then clear buffer.
+// bufferPoint keeps context of first point in current batch, then adds point into batch.
+func (w *write) bufferPoint(p bufferedPoint) error {
+	if w.batchCtx == nil {
+		w.batchCtx = p.ctx
+	}
+	return w.addPoint(p.point)
+}
+
+// addPoint merges point into aggregator if aggregation enabled, otherwise marshals point into buffer.
+func (w *write) addPoint(point *Point) error {
+	if interval := w.options().AggregateInterval(); interval > 0 && point != nil &&
+		w.aggregator.add(point, time.Duration(interval)*time.Millisecond) {
+		return nil
+	}
+	return w.batchPoint(point)
+}
+
+// flushBuffer marshals aggregated points, flushes buffer data, put data into send chan, then clear buffer.
 func (w *write) flushBuffer() {
+	for _, point := range w.aggregator.drain() {
+		if err := w.batchPoint(point); err != nil {
+			w.emitErr(err)
+		}
+	}
+	ctx := w.batchCtx
+	w.batchCtx = nil
 	if w.batchedSize == 0 {
 		return
 	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	data := w.buf.Bytes()
+	points := w.batchedSize
 	w.buf.Reset() // reset batch buf
 	w.batchedSize = 0
 
@@ -198,7 +446,8 @@ func (w *write) flushBuffer() {
 	copy(dst, data)
 
 	// put data into send chan
-	w.sendCh <- dst
+	// request is sent after caller returned, keeps values(trace context etc.) of caller's context only
+	w.sendCh <- &batch{ctx: detachedContext{Context: ctx}, data: dst, points: points}
 }
 
 // batchPoint marshals point, if success put data into buffer.
@@ -223,7 +472,7 @@ func (w *write) batchPoint(point *Point) error {
 		return nil
 	}
 	// add default tags
-	if err := addTag(w.writeOptions.DefaultTags()); err != nil {
+	if err := addTag(w.options().DefaultTags()); err != nil {
 		return err
 	}
 	// add tags of current point
@@ -233,10 +482,24 @@ func (w *write) batchPoint(point *Point) error {
 
 	// write field
 	fields := point.Fields()
+	written := 0
 	for _, f := range fields {
+		if cumulative, ok := f.(*CumulativeSum); ok {
+			// convert cumulative reading into delta
+			delta, ok := w.cumulative.delta(cumulativeKey(point, cumulative.name), point.Timestamp(), cumulative.v, w.nowFn())
+			if !ok {
+				continue
+			}
+			f = NewSum(cumulative.name, delta)
+		}
 		if err := f.write(builder); err != nil {
 			return err
 		}
+		written++
+	}
+	if written == 0 {
+		// only baseline of cumulative sum, nothing to write
+		return nil
 	}
 
 	// put point into buffer
@@ -258,37 +521,46 @@ func (w *write) sendProc() {
 		// invoke when send goroutine exit.
 		w.doneCh <- struct{}{}
 	}()
-	retryBufferLimit := w.writeOptions.RetryBufferLimit()
-	maxRetries := w.writeOptions.MaxRetries()
+	pointLimiter := ratelimit.NewLimiter(w.options().PointsPerSecond())
+	bytesLimiter := ratelimit.NewLimiter(w.options().BytesPerSecond())
 	retryBuffers := make([]*retryReq, 0)
-	retry := func(data io.Reader, attempt int) {
-		if attempt >= maxRetries {
+	retry := func(ctx context.Context, data []byte, gzipped bool, points, attempt int) {
+		opt := w.options()
+		if attempt >= opt.MaxRetries() {
 			w.emitErr(errTooManyRetry)
 			return
 		}
-		if len(retryBuffers) > retryBufferLimit {
+		if len(retryBuffers) > opt.RetryBufferLimit() {
 			w.emitErr(errTooManyRetryRequests)
 			return
 		}
 		retryBuffers = append(retryBuffers, &retryReq{
+			ctx:      ctx,
 			data:     data,
+			gzipped:  gzipped,
+			points:   points,
 			attempts: attempt + 1,
 		})
 	}
 	// send write data
-	send := func(data []byte) bool {
-		if len(data) == 0 {
+	send := func(b *batch) bool {
+		if b == nil || len(b.data) == 0 {
 			return false
 		}
 		// try compress data
-		reqData, err := w.compress(data)
+		gzipped := w.options().UseGZip()
+		reqData, err := w.compress(b.data, gzipped)
 		if err != nil {
 			w.emitErr(err)
 			return true
 		}
-		if err := w.send(reqData); err != nil {
+		if !w.throttle(pointLimiter, bytesLimiter, b.points, len(reqData)) {
+			w.emitErr(errRateLimited)
+			return false
+		}
+		if err := w.send(b.ctx, reqData, gzipped, b.points, 0); err != nil {
 			w.emitErr(err)
-			retry(reqData, 0)
+			retry(b.ctx, reqData, gzipped, b.points, 0)
 			return false
 		}
 		return true
@@ -299,26 +571,61 @@ func (w *write) sendProc() {
 			messages := retryBuffers
 			retryBuffers = make([]*retryReq, 0)
 			for _, msg := range messages {
-				if err := w.send(msg.data); err != nil {
+				if !w.throttle(pointLimiter, bytesLimiter, msg.points, len(msg.data)) {
+					w.emitErr(errRateLimited)
+					continue
+				}
+				if err := w.send(msg.ctx, msg.data, msg.gzipped, msg.points, msg.attempts); err != nil {
 					w.emitErr(err)
 					if needRetry {
-						retry(msg.data, msg.attempts)
+						retry(msg.ctx, msg.data, msg.gzipped, msg.points, msg.attempts)
 					}
 				}
 			}
 		}
 	}
+	heldBuffers := make([]*batch, 0)
+	// hold write data while paused
+	hold := func(b *batch) {
+		if b == nil || len(b.data) == 0 {
+			return
+		}
+		if len(heldBuffers) >= w.options().PauseBufferLimit() {
+			w.emitErr(errTooManyPausedRequests)
+			return
+		}
+		heldBuffers = append(heldBuffers, b)
+	}
+	// send held write data in order, stops if paused again
+	sendHeldReq := func(force bool) {
+		for len(heldBuffers) > 0 && (force || !w.paused.Load()) {
+			b := heldBuffers[0]
+			heldBuffers = heldBuffers[1:]
+			if send(b) && !force {
+				sendRetryReq(true)
+			}
+		}
+	}
 	for {
 		select {
-		case data := <-w.sendCh:
-			if send(data) {
+		case b := <-w.sendCh:
+			// resumed before resume signal handled, sends held requests first to keep order
+			sendHeldReq(false)
+			if w.paused.Load() || len(heldBuffers) > 0 {
+				hold(b)
+				continue
+			}
+			if send(b) {
 				// if send ok, retry pending failed request
 				sendRetryReq(true)
 			}
+		case <-w.resumeCh:
+			sendHeldReq(false)
 		case <-w.stopSendCh:
-			// try to send pending messages
-			for data := range w.sendCh {
-				_ = send(data)
+			// try to send held/pending messages
+			sendHeldReq(true)
+			for b := range w.sendCh {
+				_ = send(b)
 			}
 			sendRetryReq(false)
 			return
@@ -326,10 +633,58 @@ func (w *write) sendProc() {
 	}
 }
 
-// send write data to broker.
-func (w *write) send(data io.Reader) error {
-	req, _ := http.NewRequestWithContext(context.TODO(), http.MethodPut, w.endpoint, data)
-	if w.gzipWriter != nil {
+// throttle applies rate limits before sending write request, returns false if request dropped.
+// If delay mode, waits until rate limits allow or client closing, else drops request if rate limits are hit.
+func (w *write) throttle(pointLimiter, bytesLimiter *ratelimit.Limiter, points, bytes int) bool {
+	opt := w.options()
+	pointLimiter.SetLimit(opt.PointsPerSecond())
+	bytesLimiter.SetLimit(opt.BytesPerSecond())
+	if opt.RateLimitMode() == RateLimitDrop {
+		if !pointLimiter.Ready(points) || !bytesLimiter.Ready(bytes) {
+			w.throttledPoints.Add(int64(points))
+			w.droppedPoints.Add(int64(points))
+			return false
+		}
+		_ = pointLimiter.Take(points)
+		_ = bytesLimiter.Take(bytes)
+		return true
+	}
+	delay := pointLimiter.Take(points)
+	if d := bytesLimiter.Take(bytes); d > delay {
+		delay = d
+	}
+	if delay > 0 {
+		w.throttledPoints.Add(int64(points))
+		// stop waiting if write client closing
+		w.sleepFn(delay, w.stopBatchCh)
+	}
+	return true
+}
+
+// send write data to broker, creates span(child of span carried by ctx) around request if tracer set.
+// Waits if endpoint is throttled by broker, returns ThrottledError if request throttled.
+func (w *write) send(ctx context.Context, data []byte, gzipped bool, points, attempt int) (err error) {
+	brokerEndpoint := w.resolver.Endpoint()
+	w.waitThrottled(brokerEndpoint)
+	ctx, span := trace.StartSpan(ctx, w.tracer, trace.SpanWrite)
+	span.SetAttributes(
+		trace.String(trace.AttrDatabase, w.database),
+		trace.String(trace.AttrEndpoint, brokerEndpoint),
+		trace.Int(trace.AttrPointCount, points),
+		trace.Int(trace.AttrPayloadBytes, len(data)),
+		trace.Int(trace.AttrRetryAttempt, attempt),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	endpoint := fmt.Sprintf("%s/api/v1/write?db=%s", brokerEndpoint, w.database)
+	ctx = httppkg.WithHeaderHook(ctx, w.options().HeaderHook())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(data))
+	if gzipped {
 		req.Header.Set("Content-Encoding", "gzip")
 	}
 	req.Header.Set("User-Agent", httppkg.UserAgent)
@@ -351,15 +706,46 @@ func (w *write) send(data io.Reader) error {
 		if err != nil {
 			return err
 		}
+		now := w.nowFn()
+		if delay, throttled := httppkg.RetryAfter(resp, now); throttled {
+			if delay > maxRetryAfter {
+				delay = maxRetryAfter
+			}
+			w.throttledUntil[brokerEndpoint] = now.Add(delay)
+			return &ThrottledError{
+				Endpoint:   brokerEndpoint,
+				StatusCode: resp.StatusCode,
+				RetryAfter: delay,
+				Message:    string(b),
+			}
+		}
 		return errors.New(string(b))
 	}
 	// send data success
 	return nil
 }
 
-// compress request body if it needs.
-func (w *write) compress(data []byte) (*bytes.Buffer, error) {
-	if w.gzipWriter != nil {
+// waitThrottled waits until endpoint allows sending or client closing if endpoint is throttled by broker.
+func (w *write) waitThrottled(endpoint string) {
+	until, ok := w.throttledUntil[endpoint]
+	if !ok {
+		return
+	}
+	delete(w.throttledUntil, endpoint)
+	if delay := until.Sub(w.nowFn()); delay > 0 {
+		// stop waiting if write client closing
+		w.sleepFn(delay, w.stopBatchCh)
+	}
+}
+
+// compress request body if it needs, returned data is kept for retry.
+func (w *write) compress(data []byte, gzipped bool) ([]byte, error) {
+	if gzipped {
+		if w.gzipWriter == nil {
+			w.gzipBuf = &bytes.Buffer{}
+			w.gzipWriter = gzip.NewWriter(w.gzipBuf)
+		}
+		w.gzipBuf.Reset()
 		w.gzipWriter.Reset(w.gzipBuf)
 		if _, err := w.gzipWriter.Write(data); err != nil {
 			return nil, err
@@ -367,9 +753,25 @@ func (w *write) compress(data []byte) (*bytes.Buffer, error) {
 		if err := w.gzipWriter.Close(); err != nil {
 			return nil, err
 		}
-		return w.gzipBuf, nil
+		// copy data, gzip buf is reused by next request
+		compressed := make([]byte, w.gzipBuf.Len())
+		copy(compressed, w.gzipBuf.Bytes())
+		return compressed, nil
+	}
+	return data, nil
+}
+
+// sleep waits for duration, returns false if stopped before duration elapsed.
+func sleep(d time.Duration, stop <-chan struct{}) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-stop:
+		return false
 	}
-	return bytes.NewBuffer(data), nil
 }
 
 // emitErr emits error into chan.
@@ -380,3 +782,23 @@ func (w *write) emitErr(err error) {
 		// no err read, cannot put err into chan
 	}
 }
+
+// detachedContext represents the context which carries values of parent context without deadline/cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+// Deadline returns no deadline.
+func (detachedContext) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+// Done returns nil, never canceled.
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err returns nil, never canceled.
+func (detachedContext) Err() error {
+	return nil
+}
@@ -17,6 +17,46 @@
 
 package api
 
+import (
+	"fmt"
+
+	httppkg "github.com/lindb/client_go/internal/http"
+)
+
+// RateLimitMode represents the behaviour when write rate limit is hit.
+type RateLimitMode int
+
+const (
+	// RateLimitDelay delays sending write request until rate limit allows.
+	RateLimitDelay RateLimitMode = iota
+	// RateLimitDrop drops write request if rate limit is hit.
+	RateLimitDrop
+)
+
+// String returns the string value of rate limit mode.
+func (m RateLimitMode) String() string {
+	switch m {
+	case RateLimitDelay:
+		return "delay"
+	case RateLimitDrop:
+		return "drop"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(m))
+	}
+}
+
+// ParseRateLimitMode parses rate limit mode from string(delay/drop).
+func ParseRateLimitMode(mode string) (RateLimitMode, error) {
+	switch mode {
+	case "delay":
+		return RateLimitDelay, nil
+	case "drop":
+		return RateLimitDrop, nil
+	default:
+		return 0, fmt.Errorf("unknown rate limit mode: %q, expect delay/drop", mode)
+	}
+}
+
 // WriteOptions represents write configuration.
 type WriteOptions struct {
 	// Number of series sent in single write request, default 1000.
@@ -31,6 +71,20 @@ type WriteOptions struct {
 	maxRetries int
 	// Maximum number of write request to keep for retry, default 100.
 	retryBufferLimit int
+	// Maximum number of write request to hold while write client paused, default 1000.
+	pauseBufferLimit int
+	// Maximum number of points sent per second, default 0(no limit).
+	pointsPerSecond int
+	// Maximum number of request bytes(compressed if it needs) sent per second, default 0(no limit).
+	bytesPerSecond int
+	// Behaviour when rate limit is hit, default RateLimitDelay.
+	rateLimitMode RateLimitMode
+	// Header hook invoked for each write request, default nil.
+	headerHook httppkg.HeaderHook
+	// Expiry(ms) of idle series state for converting cumulative sum into delta, default 300000.
+	cumulativeExpiry int64
+	// Time slot(ms) of merging points with same series before encoding, default 0(disabled).
+	aggregateInterval int64
 }
 
 // SetBatchSize sets batch size in single write request.
@@ -102,6 +156,172 @@ func (opt *WriteOptions) RetryBufferLimit() int {
 	return opt.retryBufferLimit
 }
 
+// SetPauseBufferLimit sets maximum number of write request to hold while write client paused.
+func (opt *WriteOptions) SetPauseBufferLimit(pauseBufferLimit int) *WriteOptions {
+	opt.pauseBufferLimit = pauseBufferLimit
+	return opt
+}
+
+// PauseBufferLimit returns maximum number of write request to hold while write client paused.
+func (opt *WriteOptions) PauseBufferLimit() int {
+	return opt.pauseBufferLimit
+}
+
+// SetPointsPerSecond sets maximum number of points sent per second, 0 means no limit.
+func (opt *WriteOptions) SetPointsPerSecond(pointsPerSecond int) *WriteOptions {
+	opt.pointsPerSecond = pointsPerSecond
+	return opt
+}
+
+// PointsPerSecond returns maximum number of points sent per second.
+func (opt *WriteOptions) PointsPerSecond() int {
+	return opt.pointsPerSecond
+}
+
+// SetBytesPerSecond sets maximum number of request bytes sent per second, 0 means no limit.
+func (opt *WriteOptions) SetBytesPerSecond(bytesPerSecond int) *WriteOptions {
+	opt.bytesPerSecond = bytesPerSecond
+	return opt
+}
+
+// BytesPerSecond returns maximum number of request bytes sent per second.
+func (opt *WriteOptions) BytesPerSecond() int {
+	return opt.bytesPerSecond
+}
+
+// SetRateLimitMode sets behaviour when rate limit is hit(delay/drop).
+func (opt *WriteOptions) SetRateLimitMode(mode RateLimitMode) *WriteOptions {
+	opt.rateLimitMode = mode
+	return opt
+}
+
+// RateLimitMode returns behaviour when rate limit is hit.
+func (opt *WriteOptions) RateLimitMode() RateLimitMode {
+	return opt.rateLimitMode
+}
+
+// SetCumulativeExpiry sets expiry(ms) of idle series state for converting cumulative sum into delta.
+func (opt *WriteOptions) SetCumulativeExpiry(expiry int64) *WriteOptions {
+	opt.cumulativeExpiry = expiry
+	return opt
+}
+
+// CumulativeExpiry returns expiry(ms) of idle series state for converting cumulative sum into delta.
+func (opt *WriteOptions) CumulativeExpiry() int64 {
+	return opt.cumulativeExpiry
+}
+
+// SetAggregateInterval sets time slot(ms) of merging points with same series(namespace/metric/tags) before encoding,
+// points are merged within flush interval, 0 means disabled.
+func (opt *WriteOptions) SetAggregateInterval(interval int64) *WriteOptions {
+	opt.aggregateInterval = interval
+	return opt
+}
+
+// AggregateInterval returns time slot(ms) of merging points with same series before encoding.
+func (opt *WriteOptions) AggregateInterval() int64 {
+	return opt.aggregateInterval
+}
+
+// SetHeaderHook sets header hook invoked for each write request(batch).
+func (opt *WriteOptions) SetHeaderHook(hook httppkg.HeaderHook) *WriteOptions {
+	opt.headerHook = hook
+	return opt
+}
+
+// HeaderHook returns header hook invoked for each write request.
+func (opt *WriteOptions) HeaderHook() httppkg.HeaderHook {
+	return opt.headerHook
+}
+
+// Validate checks if write options are valid.
+func (opt *WriteOptions) Validate() error {
+	if opt.batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", opt.batchSize)
+	}
+	if opt.flushInterval <= 0 {
+		return fmt.Errorf("flush interval(ms) must be positive, got %d", opt.flushInterval)
+	}
+	if opt.maxRetries < 0 {
+		return fmt.Errorf("max retries must not be negative, got %d", opt.maxRetries)
+	}
+	if opt.retryBufferLimit < 0 {
+		return fmt.Errorf("retry buffer limit must not be negative, got %d", opt.retryBufferLimit)
+	}
+	if opt.pauseBufferLimit < 0 {
+		return fmt.Errorf("pause buffer limit must not be negative, got %d", opt.pauseBufferLimit)
+	}
+	if opt.pointsPerSecond < 0 || opt.bytesPerSecond < 0 {
+		return fmt.Errorf("rate limit must not be negative, got %d points/s, %d bytes/s", opt.pointsPerSecond, opt.bytesPerSecond)
+	}
+	if opt.rateLimitMode != RateLimitDelay && opt.rateLimitMode != RateLimitDrop {
+		return fmt.Errorf("unknown rate limit mode: %s", opt.rateLimitMode)
+	}
+	if opt.cumulativeExpiry <= 0 {
+		return fmt.Errorf("cumulative expiry(ms) must be positive, got %d", opt.cumulativeExpiry)
+	}
+	if opt.aggregateInterval < 0 {
+		return fmt.Errorf("aggregate interval(ms) must not be negative, got %d", opt.aggregateInterval)
+	}
+	for key := range opt.defaultTags {
+		if key == "" {
+			return fmt.Errorf("default tag key must not be empty")
+		}
+	}
+	return nil
+}
+
+// withDefaults returns a copy of write options, invalid values are replaced by default values.
+func (opt *WriteOptions) withDefaults() *WriteOptions {
+	cloned := opt.Clone()
+	def := DefaultWriteOptions()
+	if cloned.batchSize <= 0 {
+		cloned.batchSize = def.batchSize
+	}
+	if cloned.flushInterval <= 0 {
+		cloned.flushInterval = def.flushInterval
+	}
+	if cloned.maxRetries < 0 {
+		cloned.maxRetries = def.maxRetries
+	}
+	if cloned.retryBufferLimit < 0 {
+		cloned.retryBufferLimit = def.retryBufferLimit
+	}
+	if cloned.pauseBufferLimit < 0 {
+		cloned.pauseBufferLimit = def.pauseBufferLimit
+	}
+	if cloned.pointsPerSecond < 0 {
+		cloned.pointsPerSecond = def.pointsPerSecond
+	}
+	if cloned.bytesPerSecond < 0 {
+		cloned.bytesPerSecond = def.bytesPerSecond
+	}
+	if cloned.rateLimitMode != RateLimitDelay && cloned.rateLimitMode != RateLimitDrop {
+		cloned.rateLimitMode = def.rateLimitMode
+	}
+	if cloned.cumulativeExpiry <= 0 {
+		cloned.cumulativeExpiry = def.cumulativeExpiry
+	}
+	if cloned.aggregateInterval < 0 {
+		cloned.aggregateInterval = def.aggregateInterval
+	}
+	delete(cloned.defaultTags, "")
+	return cloned
+}
+
+// Clone returns a copy of write options(including default tags),
+// later modification of write options does not affect the copy.
+func (opt *WriteOptions) Clone() *WriteOptions {
+	cloned := *opt
+	if opt.defaultTags != nil {
+		cloned.defaultTags = make(map[string]string, len(opt.defaultTags))
+		for key, value := range opt.defaultTags {
+			cloned.defaultTags[key] = value
+		}
+	}
+	return &cloned
+}
+
 // DefaultWriteOptions creates a WriteOptions with default.
 func DefaultWriteOptions() *WriteOptions {
 	return &WriteOptions{
@@ -110,5 +330,7 @@ func DefaultWriteOptions() *WriteOptions {
 		useGZip:          true,
 		maxRetries:       3,
 		retryBufferLimit: 1_00,
+		pauseBufferLimit: 1_000,
+		cumulativeExpiry: 300_000, // 5m
 	}
 }
@@ -18,6 +18,8 @@
 package api
 
 import (
+	"context"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,20 +30,113 @@ func TestWriteOptions(t *testing.T) {
 	assert.Equal(t, int64(1_000), DefaultWriteOptions().FlushInterval())
 	assert.Equal(t, 3, DefaultWriteOptions().MaxRetries())
 	assert.Equal(t, 100, DefaultWriteOptions().RetryBufferLimit())
+	assert.Equal(t, 1_000, DefaultWriteOptions().PauseBufferLimit())
+	assert.Zero(t, DefaultWriteOptions().PointsPerSecond())
+	assert.Zero(t, DefaultWriteOptions().BytesPerSecond())
+	assert.Equal(t, RateLimitDelay, DefaultWriteOptions().RateLimitMode())
+	assert.Equal(t, int64(300_000), DefaultWriteOptions().CumulativeExpiry())
+	assert.Zero(t, DefaultWriteOptions().AggregateInterval())
 	assert.True(t, DefaultWriteOptions().UseGZip())
 	assert.Nil(t, DefaultWriteOptions().DefaultTags())
+	assert.Nil(t, DefaultWriteOptions().HeaderHook())
 
 	opt := DefaultWriteOptions().SetUseGZip(false).
 		SetFlushInterval(3_000).
 		SetBatchSize(2_000).
 		SetMaxRetries(10).
 		SetRetryBufferLimit(1_000).
+		SetPauseBufferLimit(10).
+		SetPointsPerSecond(100).
+		SetBytesPerSecond(1_000).
+		SetRateLimitMode(RateLimitDrop).
+		SetCumulativeExpiry(60_000).
+		SetAggregateInterval(10_000).
 		AddDefaultTag("k1", "v1").
-		AddDefaultTag("k2", "v2")
+		AddDefaultTag("k2", "v2").
+		SetHeaderHook(func(_ context.Context, _ http.Header) {})
 	assert.Equal(t, 2_000, opt.BatchSize())
 	assert.Equal(t, int64(3_000), opt.FlushInterval())
 	assert.Equal(t, 10, opt.MaxRetries())
 	assert.Equal(t, 1_000, opt.RetryBufferLimit())
+	assert.Equal(t, 10, opt.PauseBufferLimit())
+	assert.Equal(t, 100, opt.PointsPerSecond())
+	assert.Equal(t, 1_000, opt.BytesPerSecond())
+	assert.Equal(t, RateLimitDrop, opt.RateLimitMode())
+	assert.Equal(t, int64(60_000), opt.CumulativeExpiry())
+	assert.Equal(t, int64(10_000), opt.AggregateInterval())
 	assert.False(t, opt.UseGZip())
 	assert.Equal(t, map[string]string{"k1": "v1", "k2": "v2"}, opt.DefaultTags())
+	assert.NotNil(t, opt.HeaderHook())
+}
+
+func TestWriteOptions_Validate(t *testing.T) {
+	assert.NoError(t, DefaultWriteOptions().Validate())
+	assert.NoError(t, DefaultWriteOptions().SetMaxRetries(0).SetRetryBufferLimit(0).Validate())
+
+	cases := []struct {
+		name string
+		opt  *WriteOptions
+	}{
+		{name: "zero batch size", opt: DefaultWriteOptions().SetBatchSize(0)},
+		{name: "negative batch size", opt: DefaultWriteOptions().SetBatchSize(-1)},
+		{name: "zero flush interval", opt: DefaultWriteOptions().SetFlushInterval(0)},
+		{name: "negative flush interval", opt: DefaultWriteOptions().SetFlushInterval(-1)},
+		{name: "negative max retries", opt: DefaultWriteOptions().SetMaxRetries(-1)},
+		{name: "negative retry buffer limit", opt: DefaultWriteOptions().SetRetryBufferLimit(-1)},
+		{name: "negative pause buffer limit", opt: DefaultWriteOptions().SetPauseBufferLimit(-1)},
+		{name: "negative points per second", opt: DefaultWriteOptions().SetPointsPerSecond(-1)},
+		{name: "negative bytes per second", opt: DefaultWriteOptions().SetBytesPerSecond(-1)},
+		{name: "unknown rate limit mode", opt: DefaultWriteOptions().SetRateLimitMode(RateLimitMode(10))},
+		{name: "zero cumulative expiry", opt: DefaultWriteOptions().SetCumulativeExpiry(0)},
+		{name: "negative aggregate interval", opt: DefaultWriteOptions().SetAggregateInterval(-1)},
+		{name: "empty tag key", opt: DefaultWriteOptions().AddDefaultTag("", "v")},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.opt.Validate())
+		})
+	}
+}
+
+func TestWriteOptions_Clone(t *testing.T) {
+	opt := DefaultWriteOptions()
+	cloned := opt.Clone()
+	assert.Equal(t, opt, cloned)
+	assert.Nil(t, cloned.DefaultTags())
+
+	opt.AddDefaultTag("k1", "v1")
+	cloned = opt.Clone()
+	assert.Equal(t, opt, cloned)
+
+	opt.AddDefaultTag("k2", "v2").SetBatchSize(10)
+	assert.Equal(t, map[string]string{"k1": "v1"}, cloned.DefaultTags())
+	assert.Equal(t, 1_000, cloned.BatchSize())
+}
+
+func TestRateLimitMode(t *testing.T) {
+	for _, mode := range []RateLimitMode{RateLimitDelay, RateLimitDrop} {
+		parsed, err := ParseRateLimitMode(mode.String())
+		assert.NoError(t, err)
+		assert.Equal(t, mode, parsed)
+	}
+	assert.Equal(t, "unknown(10)", RateLimitMode(10).String())
+	_, err := ParseRateLimitMode("block")
+	assert.Error(t, err)
+}
+
+func TestWriteOptions_WithDefaults(t *testing.T) {
+	def := DefaultWriteOptions()
+	assert.Equal(t, def, def.withDefaults())
+
+	opt := DefaultWriteOptions().SetBatchSize(0).SetFlushInterval(-1).SetMaxRetries(-1).
+		SetRetryBufferLimit(-1).SetPauseBufferLimit(-1).SetPointsPerSecond(-1).SetBytesPerSecond(-1).
+		SetRateLimitMode(RateLimitMode(10)).SetCumulativeExpiry(0).SetAggregateInterval(-1).
+		AddDefaultTag("", "v").AddDefaultTag("k", "v")
+	assert.Error(t, opt.Validate())
+	fixed := opt.withDefaults()
+	assert.NoError(t, fixed.Validate())
+	assert.Equal(t, def.AddDefaultTag("k", "v"), fixed)
+	// original options not modified
+	assert.Equal(t, 0, opt.BatchSize())
 }
@@ -19,18 +19,108 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lindb/client_go/internal/discovery"
 	httppkg "github.com/lindb/client_go/internal/http"
 )
 
+// writeRequest represents the write request received by writeServer.
+type writeRequest struct {
+	encoding string
+	body     []byte
+}
+
+// writeServer represents the broker which records write requests in test, concurrent safe.
+type writeServer struct {
+	*httptest.Server
+	received []writeRequest
+	mutex    sync.Mutex
+}
+
+// newWriteServer creates a writeServer closed after test, respond writes response of n-th(from 1) request,
+// returns false to respond ok.
+func newWriteServer(t *testing.T, respond func(n int, w http.ResponseWriter) bool) *writeServer {
+	s := &writeServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mutex.Lock()
+		s.received = append(s.received, writeRequest{encoding: r.Header.Get("Content-Encoding"), body: body})
+		n := len(s.received)
+		s.mutex.Unlock()
+		if respond != nil && respond(n, w) {
+			return
+		}
+		_, _ = w.Write([]byte(`ok`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// requests returns the received write requests.
+func (s *writeServer) requests() []writeRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]writeRequest(nil), s.received...)
+}
+
+// metrics returns the metric names(of given names) in order of received(uncompressed) requests.
+func (s *writeServer) metrics(names ...string) []string {
+	var metrics []string
+	for _, req := range s.requests() {
+		for _, name := range names {
+			if strings.Contains(string(req.body), name) {
+				metrics = append(metrics, name)
+			}
+		}
+	}
+	return metrics
+}
+
+// sleepRecorder records delays of sleep without sleeping, concurrent safe.
+type sleepRecorder struct {
+	delays []time.Duration
+	mutex  sync.Mutex
+}
+
+func (r *sleepRecorder) sleep(d time.Duration, _ <-chan struct{}) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.delays = append(r.delays, d)
+	return true
+}
+
+// recorded returns the recorded delays.
+func (r *sleepRecorder) recorded() []time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]time.Duration(nil), r.delays...)
+}
+
+// newTestWrite creates a write client sending to server, modify(if not nil) replaces clock/sleep before started.
+func newTestWrite(svr *writeServer, opt *WriteOptions, modify func(w *write)) *write {
+	w := newWrite(discovery.NewStaticResolver(svr.URL), "test", opt.withDefaults(), httppkg.DefaultOptions())
+	if modify != nil {
+		modify(w)
+	}
+	w.start()
+	return w
+}
+
 func TestNewWrite(t *testing.T) {
 	w := NewWrite("http://localhost:9000", "test",
 		DefaultWriteOptions(), httppkg.DefaultOptions())
@@ -41,12 +131,30 @@ func TestNewWrite(t *testing.T) {
 	w.Close() // ignore it
 }
 
-func TestWriteData(t *testing.T) {
-	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, _ = w.Write([]byte(`ok`))
-	}))
-	defer svr.Close()
+func TestNewWrite_InvalidOptions(t *testing.T) {
+	// invalid options replaced by default values
+	w := NewWrite("http://localhost:9000", "test",
+		DefaultWriteOptions().SetFlushInterval(0), httppkg.DefaultOptions())
+	assert.Equal(t, DefaultWriteOptions().FlushInterval(), w.(*write).options().FlushInterval())
+	w.Close()
+}
 
+func TestWriteData_OptionsSnapshot(t *testing.T) {
+	svr := newWriteServer(t, nil)
+	opt := DefaultWriteOptions().SetUseGZip(false).AddDefaultTag("key", "value")
+	w := NewWrite(svr.URL, "test", opt, httppkg.DefaultOptions())
+	// modify options after write client created
+	opt.AddDefaultTag("key", "modified").SetUseGZip(true)
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 10.0)))
+	w.Close()
+	requests := svr.requests()
+	assert.Len(t, requests, 1)
+	assert.Contains(t, string(requests[0].body), "value")
+	assert.NotContains(t, string(requests[0].body), "modified")
+}
+
+func TestWriteData(t *testing.T) {
+	svr := newWriteServer(t, nil)
 	w := NewWrite(svr.URL, "test",
 		DefaultWriteOptions().AddDefaultTag("key", "value"), httppkg.DefaultOptions())
 	for i := 0; i < 10; i++ {
@@ -57,12 +165,11 @@ func TestWriteData(t *testing.T) {
 }
 
 func TestWriteData_Failure(t *testing.T) {
-	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	svr := newWriteServer(t, func(_ int, w http.ResponseWriter) bool {
 		w.WriteHeader(http.StatusInternalServerError)
 		_, _ = w.Write([]byte(`error`))
-	}))
-	defer svr.Close()
-
+		return true
+	})
 	w := NewWrite(svr.URL, "test",
 		DefaultWriteOptions().SetMaxRetries(2).SetBatchSize(1).
 			SetRetryBufferLimit(50), httppkg.DefaultOptions())
@@ -73,13 +180,307 @@ func TestWriteData_Failure(t *testing.T) {
 	w.Close()
 }
 
+func TestWriteData_Retry(t *testing.T) {
+	svr := newWriteServer(t, func(n int, w http.ResponseWriter) bool {
+		if n == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return true
+		}
+		return false
+	})
+	w := NewWrite(svr.URL, "test", DefaultWriteOptions().SetBatchSize(1), httppkg.DefaultOptions())
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 1.0)))
+	// make sure points are sent by separate requests
+	assert.Eventually(t, func() bool {
+		return len(svr.requests()) == 1
+	}, time.Second, time.Millisecond)
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 2.0)))
+	w.Close()
+	// failed request retried with same body
+	requests := svr.requests()
+	assert.Len(t, requests, 3)
+	assert.Equal(t, requests[0].body, requests[2].body)
+	assert.NotEqual(t, requests[0].body, requests[1].body)
+}
+
+func TestWrite_Update(t *testing.T) {
+	svr := newWriteServer(t, nil)
+	encodings := func() []string {
+		var encodings []string
+		for _, req := range svr.requests() {
+			encodings = append(encodings, req.encoding)
+		}
+		return encodings
+	}
+
+	opt := DefaultWriteOptions().SetBatchSize(10).SetFlushInterval(time.Hour.Milliseconds()).SetUseGZip(false)
+	w := NewWrite(svr.URL, "test", opt, httppkg.DefaultOptions())
+	updater, ok := w.(WriteUpdater)
+	assert.True(t, ok)
+	for i := 0; i < 3; i++ {
+		w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 1.0)))
+	}
+	// invalid options rejected, keep current options
+	assert.Error(t, updater.Update(func(opt *WriteOptions) { opt.SetBatchSize(2).SetFlushInterval(0) }))
+	assert.Equal(t, 10, w.(*write).options().BatchSize())
+	assert.Empty(t, svr.requests())
+
+	// shrink batch size, flush buffered points
+	assert.NoError(t, updater.Update(func(opt *WriteOptions) { opt.SetBatchSize(2) }))
+	assert.Eventually(t, func() bool { return len(svr.requests()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// shorten flush interval, reset ticker
+	assert.NoError(t, updater.Update(func(opt *WriteOptions) { opt.SetFlushInterval(10) }))
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 1.0)))
+	assert.Eventually(t, func() bool { return len(svr.requests()) == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// enable gzip/default tags for next request
+	assert.NoError(t, updater.Update(func(opt *WriteOptions) { opt.SetUseGZip(true).AddDefaultTag("key", "value") }))
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 1.0)))
+	assert.Eventually(t, func() bool { return len(svr.requests()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"", "", "gzip"}, encodings())
+	// options given by caller not modified
+	assert.Equal(t, 10, opt.BatchSize())
+	assert.Nil(t, opt.DefaultTags())
+
+	// modify invoked without lock, invoked again with latest options if options updated concurrently
+	calls := 0
+	assert.NoError(t, updater.Update(func(opt *WriteOptions) {
+		calls++
+		if calls == 1 {
+			assert.NoError(t, updater.Update(func(opt *WriteOptions) { opt.AddDefaultTag("k1", "v1") }))
+		}
+		opt.AddDefaultTag("k2", "v2")
+	}))
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, map[string]string{"key": "value", "k1": "v1", "k2": "v2"}, w.(*write).options().DefaultTags())
+
+	w.Close()
+	assert.Equal(t, errWriteClosed, updater.Update(func(opt *WriteOptions) { opt.SetBatchSize(1) }))
+}
+
+func TestWrite_PauseResume(t *testing.T) {
+	svr := newWriteServer(t, nil)
+	w := newTestWrite(svr, DefaultWriteOptions().SetBatchSize(1).SetUseGZip(false), nil)
+	assert.Implements(t, (*WritePauser)(nil), w)
+	assert.False(t, w.Paused())
+	w.Resume() // ignore it
+	w.Pause()
+	assert.True(t, w.Paused())
+	for _, name := range []string{"cpu1", "cpu2", "cpu3"} {
+		w.AddPoint(context.TODO(), NewPoint(name).AddField(NewLast("load", 1.0)))
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, svr.requests())
+
+	// held requests sent in order
+	w.Resume()
+	assert.False(t, w.Paused())
+	assert.Eventually(t, func() bool { return len(svr.requests()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"cpu1", "cpu2", "cpu3"}, svr.metrics("cpu1", "cpu2", "cpu3"))
+
+	// close sends held requests
+	w.Pause()
+	w.AddPoint(context.TODO(), NewPoint("cpu4").AddField(NewLast("load", 1.0)))
+	w.Close()
+	assert.Equal(t, []string{"cpu1", "cpu2", "cpu3", "cpu4"}, svr.metrics("cpu1", "cpu2", "cpu3", "cpu4"))
+}
+
+func TestWrite_ResumeWithQueuedBatch(t *testing.T) {
+	svr := newWriteServer(t, nil)
+	w := newTestWrite(svr, DefaultWriteOptions().SetBatchSize(1).SetUseGZip(false), nil)
+	w.Pause()
+	w.AddPoint(context.TODO(), NewPoint("cpu1").AddField(NewLast("load", 1.0)))
+	w.AddPoint(context.TODO(), NewPoint("cpu2").AddField(NewLast("load", 1.0)))
+	// batch process takes cpu2 after cpu1 received by send process(held)
+	assert.Eventually(t, func() bool { return len(w.bufferCh) == 0 }, time.Second, time.Millisecond)
+	// resumed, but batch of cpu2 received before resume signal
+	w.paused.Store(false)
+	w.AddPoint(context.TODO(), NewPoint("cpu3").AddField(NewLast("load", 1.0)))
+	w.Close()
+	assert.Equal(t, []string{"cpu1", "cpu2", "cpu3"}, svr.metrics("cpu1", "cpu2", "cpu3"))
+}
+
+func TestWrite_PauseBufferLimit(t *testing.T) {
+	svr := newWriteServer(t, nil)
+	w := newTestWrite(svr, DefaultWriteOptions().SetBatchSize(1).SetPauseBufferLimit(1), nil)
+	errCh := w.Errors()
+	w.Pause()
+	// keep adding points until error read, error is dropped if no reader
+	done := make(chan struct{})
+	var wait sync.WaitGroup
+	wait.Add(1)
+	go func() {
+		defer wait.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 1.0)))
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+	assert.Equal(t, errTooManyPausedRequests, <-errCh)
+	close(done)
+	wait.Wait()
+	w.Close()
+}
+
+func TestWrite_RateLimit(t *testing.T) {
+	write := func(opt *WriteOptions, points int) (int, WriteStats, []time.Duration) {
+		svr := newWriteServer(t, nil)
+		sleeper := &sleepRecorder{}
+		w := newTestWrite(svr, opt.SetBatchSize(1).SetUseGZip(false), func(w *write) {
+			w.sleepFn = sleeper.sleep
+		})
+		assert.Implements(t, (*WriteStatsReporter)(nil), w)
+		for i := 0; i < points; i++ {
+			w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", float64(i))))
+			// wait point sent or dropped, one point per request
+			assert.Eventually(t, func() bool {
+				return len(svr.requests())+int(w.Stats().DroppedPoints) == i+1
+			}, 5*time.Second, time.Millisecond)
+		}
+		w.Close()
+		return len(svr.requests()), w.Stats(), sleeper.recorded()
+	}
+
+	t.Run("delay if points limit hit", func(t *testing.T) {
+		count, stats, delays := write(DefaultWriteOptions().SetPointsPerSecond(2), 4)
+		assert.Equal(t, 4, count)
+		assert.Equal(t, WriteStats{ThrottledPoints: 2}, stats)
+		assert.Len(t, delays, 2)
+		assert.Greater(t, delays[1], delays[0])
+	})
+	t.Run("drop if points limit hit", func(t *testing.T) {
+		count, stats, delays := write(DefaultWriteOptions().SetPointsPerSecond(1).SetRateLimitMode(RateLimitDrop), 3)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, WriteStats{ThrottledPoints: 2, DroppedPoints: 2}, stats)
+		assert.Empty(t, delays)
+	})
+	t.Run("drop if bytes limit hit", func(t *testing.T) {
+		count, stats, _ := write(DefaultWriteOptions().SetBytesPerSecond(1).SetRateLimitMode(RateLimitDrop), 2)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, WriteStats{ThrottledPoints: 1, DroppedPoints: 1}, stats)
+	})
+	t.Run("no limit", func(t *testing.T) {
+		count, stats, delays := write(DefaultWriteOptions(), 10)
+		assert.Equal(t, 10, count)
+		assert.Equal(t, WriteStats{}, stats)
+		assert.Empty(t, delays)
+	})
+}
+
+func TestWrite_RateLimit_Retry(t *testing.T) {
+	svr := newWriteServer(t, func(n int, w http.ResponseWriter) bool {
+		if n == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return true
+		}
+		return false
+	})
+	sleeper := &sleepRecorder{}
+	w := newTestWrite(svr, DefaultWriteOptions().SetBatchSize(1).SetPointsPerSecond(1), func(w *write) {
+		w.sleepFn = sleeper.sleep
+	})
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 1.0)))
+	assert.Eventually(t, func() bool { return len(svr.requests()) == 1 }, time.Second, time.Millisecond)
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 2.0)))
+	w.Close()
+	// retried request is delayed by rate limit too
+	assert.Len(t, svr.requests(), 3)
+	assert.Len(t, sleeper.recorded(), 2)
+	assert.Equal(t, WriteStats{ThrottledPoints: 2}, w.Stats())
+}
+
+func TestWrite_RateLimit_Close(t *testing.T) {
+	svr := newWriteServer(t, nil)
+	w := newTestWrite(svr, DefaultWriteOptions().SetBatchSize(1).SetBytesPerSecond(1), nil)
+	for i := 0; i < 3; i++ {
+		w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", float64(i))))
+	}
+	// waiting for rate limit(minutes) interrupted by close
+	start := time.Now()
+	w.Close()
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int64(3), w.Stats().ThrottledPoints)
+}
+
+func TestSleep(t *testing.T) {
+	assert.True(t, sleep(time.Millisecond, nil))
+	stop := make(chan struct{})
+	close(stop)
+	assert.False(t, sleep(time.Hour, stop))
+}
+
+func TestWrite_Throttled(t *testing.T) {
+	svr := newWriteServer(t, func(n int, w http.ResponseWriter) bool {
+		if n == 1 {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte("slow down"))
+			return true
+		}
+		return false
+	})
+	now := time.Now()
+	sleeper := &sleepRecorder{}
+	w := newTestWrite(svr, DefaultWriteOptions().SetBatchSize(1), func(w *write) {
+		w.nowFn = func() time.Time { return now }
+		w.sleepFn = sleeper.sleep
+	})
+	errs := make(chan error, 10)
+	go func() {
+		for err := range w.Errors() {
+			errs <- err
+		}
+	}()
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 1.0)))
+	err := <-errs
+	assert.True(t, errors.Is(err, ErrThrottled))
+	var throttledErr *ThrottledError
+	assert.True(t, errors.As(err, &throttledErr))
+	assert.Equal(t, http.StatusTooManyRequests, throttledErr.StatusCode)
+	assert.Equal(t, svr.URL, throttledErr.Endpoint)
+	assert.Equal(t, maxRetryAfter, throttledErr.RetryAfter)
+	assert.Equal(t, "slow down", throttledErr.Message)
+	assert.Contains(t, err.Error(), "retry after 1m0s")
+
+	// next request delayed, then throttled request retried
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 2.0)))
+	w.Close()
+	assert.Equal(t, []time.Duration{maxRetryAfter}, sleeper.recorded())
+	assert.Len(t, svr.requests(), 3)
+}
+
+func TestWrite_Throttled_Close(t *testing.T) {
+	svr := newWriteServer(t, func(n int, w http.ResponseWriter) bool {
+		if n == 1 {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return true
+		}
+		return false
+	})
+	w := NewWrite(svr.URL, "test", DefaultWriteOptions().SetBatchSize(1), httppkg.DefaultOptions())
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 1.0)))
+	assert.True(t, errors.Is(<-w.Errors(), ErrThrottled))
+	w.AddPoint(context.TODO(), NewPoint("cpu").AddField(NewLast("load", 2.0)))
+	// waiting for broker throttle(1 minute) interrupted by close
+	start := time.Now()
+	w.Close()
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
 func TestAddPoint(t *testing.T) {
 	t.Run("invalid point", func(t *testing.T) {
 		w := write{}
 		w.AddPoint(context.TODO(), NewPoint("cpu"))
 	})
 	t.Run("add point timeout", func(t *testing.T) {
-		w := write{bufferCh: make(chan *Point)}
+		w := write{bufferCh: make(chan bufferedPoint)}
 		ctx, cancel := context.WithTimeout(context.TODO(), time.Millisecond*10)
 		defer cancel()
 		w.AddPoint(ctx, NewPoint("cpu").AddField(NewLast("load", 10.0)))
@@ -87,10 +488,7 @@ func TestAddPoint(t *testing.T) {
 }
 
 func TestAddWrongPoint(t *testing.T) {
-	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, _ = w.Write([]byte(`ok`))
-	}))
-	defer svr.Close()
+	svr := newWriteServer(t, nil)
 
 	t.Run("wrong common tags", func(t *testing.T) {
 		w := NewWrite(svr.URL, "test",
@@ -0,0 +1,40 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lindb
+
+import (
+	"github.com/lindb/client_go/internal/http"
+)
+
+// ErrCircuitOpen represents the error returned without sending write/query request if circuit breaker of endpoint is open.
+var ErrCircuitOpen = http.ErrCircuitOpen
+
+// CircuitState represents the state of circuit breaker.
+type CircuitState = http.CircuitState
+
+const (
+	// CircuitClosed represents requests are sent normally.
+	CircuitClosed = http.CircuitClosed
+	// CircuitOpen represents requests fail fast until cool-down elapsed.
+	CircuitOpen = http.CircuitOpen
+	// CircuitHalfOpen represents a single probe request is sent, other requests fail fast.
+	CircuitHalfOpen = http.CircuitHalfOpen
+)
+
+// CircuitStateHook represents the hook invoked when circuit state of endpoint(host:port) changed.
+type CircuitStateHook = http.CircuitStateHook
@@ -17,48 +17,169 @@
 
 package lindb
 
-import "github.com/lindb/client_go/api"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lindb/common/models"
+
+	"github.com/lindb/client_go/api"
+	"github.com/lindb/client_go/internal/discovery"
+	httppkg "github.com/lindb/client_go/internal/http"
+)
 
 // Client represents the api to communicate with LinDB backend server.
 // Ref InfluxDB client: https://github.com/influxdata/influxdb-client-go
 type Client interface {
-	// Write returns an asynchronous write client.
+	// Write returns an asynchronous write client, uses default database of options if database is empty.
 	Write(database string) api.Write
-	// DataQuery returns a metric data query client.
+	// DataQuery returns a metric data query client, uses default database of options if database is empty.
 	DataQuery() api.DataQuery
+	// Close closes all write clients created by this client(flushes pending data), stops discovering broker nodes,
+	// then releases idle connections, returns ctx.Err() if ctx done before all writes closed.
+	Close(ctx context.Context) error
 }
 
 // client implements the Client interface.
 type client struct {
-	brokerEndpoint string
-	options        *Options
+	database     string            // default database when client created
+	writeOptions *api.WriteOptions // snapshot of write options when client created
+	httpOptions  *httppkg.Options  // copy of http options when client created, owns transport of client
+	resolver     discovery.Resolver
+	httpClient   *http.Client // shared by all write/query clients of this client
+
+	writes []api.Write
+	mutex  sync.Mutex
 }
 
-// NewClientWithOptions creates a Client with backend endpoint and options.
+// NewClientWithOptions creates a Client with backend endpoint and options, invalid options are replaced by default values,
+// use Options.Validate to check options. If discovery enabled, backend endpoint is used as seed endpoint for discovering
+// live broker nodes. Options are copied, later modification does not affect created client.
 func NewClientWithOptions(brokerEndpoint string, options *Options) Client {
+	return newClient([]string{brokerEndpoint}, options)
+}
+
+// newValidClient creates a client with backend endpoints and options, returns error if options are invalid.
+func newValidClient(brokerEndpoints []string, options *Options) (*client, error) {
 	if options == nil {
 		options = DefaultOptions()
 	}
+	if err := options.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+	return newClient(brokerEndpoints, options), nil
+}
+
+// newClient creates a client with backend endpoints and options, requests are sent to endpoints by round-robin.
+// If discovery enabled, backend endpoints are used as seed endpoints for discovering live broker nodes.
+// Options are copied, later modification does not affect created client.
+func newClient(brokerEndpoints []string, options *Options) *client {
+	if options == nil {
+		options = DefaultOptions()
+	}
+	// each client owns its transport, closing client does not affect other clients created by same options
+	httpOptions := options.HTTPOptions().Clone()
+	httpClient := httpOptions.HTTPClient()
+	var resolver discovery.Resolver
+	if options.Discovery() {
+		interval := time.Duration(options.DiscoveryInterval()) * time.Second
+		if interval <= 0 {
+			interval = time.Duration(DefaultOptions().DiscoveryInterval()) * time.Second
+		}
+		resolver = discovery.NewBrokerResolver(httpClient, interval, brokerEndpoints[0], brokerEndpoints[1:]...)
+	} else {
+		resolver = discovery.NewStaticResolver(brokerEndpoints[0], brokerEndpoints[1:]...)
+	}
 	return &client{
-		brokerEndpoint: brokerEndpoint,
-		options:        options,
+		database:     options.Database(),
+		writeOptions: options.WriteOptions().Clone(),
+		httpOptions:  httpOptions,
+		resolver:     resolver,
+		httpClient:   httpClient,
 	}
 }
 
 // NewClient creates a Client with backend endpoint and default options.
 func NewClient(brokerEndpoint string) Client {
-	return &client{
-		brokerEndpoint: brokerEndpoint,
-		options:        DefaultOptions(),
-	}
+	return NewClientWithOptions(brokerEndpoint, DefaultOptions())
 }
 
-// Write returns an asynchronous write client.
+// Write returns an asynchronous write client, uses default database of options if database is empty.
 func (c *client) Write(database string) api.Write {
-	return api.NewWrite(c.brokerEndpoint, database, c.options.WriteOptions(), c.options.HTTPOptions())
+	if database == "" {
+		database = c.database
+	}
+	w := api.NewWriteWithResolver(c.resolver, database, c.writeOptions, c.httpOptions)
+
+	c.mutex.Lock()
+	c.writes = append(c.writes, w)
+	c.mutex.Unlock()
+	return w
 }
 
-// DataQuery returns a metric data query client.
+// DataQuery returns a metric data query client, uses default database of options if database is empty.
 func (c *client) DataQuery() api.DataQuery {
-	return api.NewDataQuery(c.brokerEndpoint, c.options.HTTPOptions())
+	query := api.NewDataQueryWithResolver(c.resolver, c.httpOptions)
+	if c.database == "" {
+		return query
+	}
+	return &defaultDatabaseQuery{query: query, database: c.database}
+}
+
+// Close closes all write clients created by this client(flushes pending data), stops discovering broker nodes,
+// then releases idle connections, returns ctx.Err() if ctx done before all writes closed.
+func (c *client) Close(ctx context.Context) error {
+	c.mutex.Lock()
+	writes := c.writes
+	c.writes = nil
+	c.mutex.Unlock()
+	// stop refreshing broker nodes, known endpoints still used by closing write clients
+	defer c.resolver.Close()
+
+	done := make(chan struct{})
+	go func() {
+		var wait sync.WaitGroup
+		wait.Add(len(writes))
+		for _, w := range writes {
+			go func(w api.Write) {
+				defer wait.Done()
+				w.Close()
+			}(w)
+		}
+		wait.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		c.httpClient.CloseIdleConnections()
+		return nil
+	}
+}
+
+// defaultDatabaseQuery represents the data query client which uses default database if database is empty.
+type defaultDatabaseQuery struct {
+	query    api.DataQuery
+	database string
+}
+
+// DataQuery queries time series data from database(default database if empty) by given ql.
+func (q *defaultDatabaseQuery) DataQuery(ctx context.Context, database, ql string) (*models.ResultSet, error) {
+	if database == "" {
+		database = q.database
+	}
+	return q.query.DataQuery(ctx, database, ql)
+}
+
+// MetadataQuery queries metric metadata from database(default database if empty) by given ql.
+func (q *defaultDatabaseQuery) MetadataQuery(ctx context.Context, database, ql string) (*models.Metadata, error) {
+	if database == "" {
+		database = q.database
+	}
+	return q.query.MetadataQuery(ctx, database, ql)
 }
@@ -20,6 +20,10 @@ package lindb
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -41,6 +45,47 @@ func TestClient_DataQuery(t *testing.T) {
 	assert.NotNil(t, c.DataQuery())
 }
 
+func TestClient_DefaultDatabase(t *testing.T) {
+	c := NewClientWithOptions("http://localhost:8080", DefaultOptions().SetDatabase("test"))
+	assert.IsType(t, &defaultDatabaseQuery{}, c.DataQuery())
+	assert.NotNil(t, c.Write(""))
+	assert.NoError(t, c.Close(context.TODO()))
+}
+
+func TestClient_InvalidOptions(t *testing.T) {
+	// invalid options replaced by default values
+	c := NewClientWithOptions("http://localhost:8080", DefaultOptions().SetFlushInterval(-1).
+		SetReqTimeout(0).SetDiscovery(true).SetDiscoveryInterval(0))
+	assert.NotNil(t, c.Write("test"))
+	assert.Equal(t, 30*time.Second, c.(*client).httpClient.Timeout)
+	assert.NoError(t, c.Close(context.TODO()))
+
+	c, err := NewClientFromDSN("lindb://localhost?batchSize=0")
+	assert.ErrorContains(t, err, "invalid write options")
+	assert.Nil(t, c)
+}
+
+func TestClient_OptionsSnapshot(t *testing.T) {
+	opt := DefaultOptions().AddDefaultTag("k1", "v1").SetDatabase("db1").SetReqTimeout(10)
+	c := NewClientWithOptions("http://localhost:8080", opt)
+	// modify options after client created
+	opt.AddDefaultTag("k2", "v2").SetBatchSize(-1).SetDatabase("db2").SetReqTimeout(20)
+	assert.Equal(t, map[string]string{"k1": "v1"}, c.(*client).writeOptions.DefaultTags())
+	assert.Equal(t, "db1", c.(*client).database)
+	assert.Equal(t, 10*time.Second, c.(*client).httpClient.Timeout)
+	assert.Equal(t, int64(10), c.(*client).httpOptions.ReqTimeout())
+	assert.NotNil(t, c.Write("test"))
+	assert.NoError(t, c.Close(context.TODO()))
+}
+
+func TestClient_Discovery(t *testing.T) {
+	c := NewClientWithOptions("http://127.0.0.1:0", DefaultOptions().SetDiscovery(true))
+	assert.Equal(t, []string{"http://127.0.0.1:0"}, c.(*client).resolver.Endpoints())
+	assert.NotNil(t, c.Write("test"))
+	assert.NotNil(t, c.DataQuery())
+	assert.NoError(t, c.Close(context.TODO()))
+}
+
 func TestWrite(t *testing.T) {
 	cli := NewClient("http://localhost:9000")
 	w := cli.Write("_internal")
@@ -59,3 +104,100 @@ func TestWrite(t *testing.T) {
 	w.Close()
 	time.Sleep(time.Second)
 }
+
+func TestClient_Close(t *testing.T) {
+	var received atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		_, _ = w.Write([]byte(`ok`))
+	}))
+	defer svr.Close()
+
+	cli := NewClientWithOptions(svr.URL, DefaultOptions().SetFlushInterval(60_000))
+	assert.Same(t, cli.(*client).httpClient, cli.(*client).httpOptions.HTTPClient())
+	for _, db := range []string{"db1", "db2"} {
+		w := cli.Write(db)
+		w.AddPoint(context.TODO(), api.NewPoint("cpu").AddField(api.NewSum("load", 1.0)))
+	}
+	assert.NoError(t, cli.Close(context.TODO()))
+	// pending data flushed
+	assert.Equal(t, int32(2), received.Load())
+	// close again
+	assert.NoError(t, cli.Close(context.TODO()))
+}
+
+func TestClient_Close_OwnTransport(t *testing.T) {
+	var closed atomic.Int32
+	svr := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	svr.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed.Add(1)
+		}
+	}
+	svr.Start()
+	defer svr.Close()
+
+	opt := DefaultOptions().AddHeader("k", "v")
+	cli1 := NewClientWithOptions(svr.URL, opt)
+	cli2 := NewClientWithOptions(svr.URL, opt)
+	assert.NotSame(t, cli1.(*client).httpClient, cli2.(*client).httpClient)
+	for _, cli := range []Client{cli1, cli2} {
+		_, err := cli.DataQuery().DataQuery(context.TODO(), "test", "select load from cpu")
+		assert.NoError(t, err)
+	}
+	// idle connection of closed client released, connection of other client kept
+	assert.NoError(t, cli1.Close(context.TODO()))
+	assert.Eventually(t, func() bool { return closed.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), closed.Load())
+	assert.NoError(t, cli2.Close(context.TODO()))
+	assert.Eventually(t, func() bool { return closed.Load() == 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestClient_Close_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte(`ok`))
+	}))
+	defer svr.Close()
+	defer close(release)
+
+	cli := NewClient(svr.URL)
+	w := cli.Write("test")
+	w.AddPoint(context.TODO(), api.NewPoint("cpu").AddField(api.NewSum("load", 1.0)))
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, cli.Close(ctx), context.DeadlineExceeded)
+}
+
+// countingRoundTripper counts requests sent through it.
+type countingRoundTripper struct {
+	count atomic.Int32
+}
+
+func (rt *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.count.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClient_RoundTripper(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer svr.Close()
+
+	rt := &countingRoundTripper{}
+	cli := NewClientWithOptions(svr.URL, DefaultOptions().SetRoundTripper(rt))
+	_, err := cli.DataQuery().DataQuery(context.TODO(), "test", "select load from cpu")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), rt.count.Load())
+
+	w := cli.Write("test")
+	w.AddPoint(context.TODO(), api.NewPoint("cpu").AddField(api.NewSum("load", 1.0)))
+	assert.NoError(t, cli.Close(context.TODO()))
+	assert.Equal(t, int32(2), rt.count.Load())
+}
@@ -0,0 +1,507 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lindb
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/lindb/common/pkg/ltoml"
+
+	"github.com/lindb/client_go/api"
+)
+
+// envPrefix represents the prefix of environment variable which overrides config,
+// e.g. LINDB_WRITE_BATCH_SIZE overrides write.batch-size.
+const envPrefix = "LINDB_"
+
+// Config represents the client configuration which can be loaded from TOML/YAML file
+// and overridden by environment variables, converts to Options by Config.Options.
+type Config struct {
+	// Broker endpoints(or seed endpoints if discovery enabled), requests are sent by round-robin.
+	Endpoints []string `toml:"endpoints" yaml:"endpoints"`
+	// Default database used if database is empty.
+	Database string `toml:"database" yaml:"database"`
+	// Whether to discover live broker nodes from cluster state.
+	Discovery bool `toml:"discovery" yaml:"discovery"`
+	// Interval of refreshing live broker nodes.
+	DiscoveryInterval ltoml.Duration `toml:"discovery-interval" yaml:"discovery-interval"`
+	// Write options.
+	Write WriteConfig `toml:"write" yaml:"write"`
+	// HTTP options.
+	HTTP HTTPConfig `toml:"http" yaml:"http"`
+}
+
+// WriteConfig represents the configuration of write client, maps to api.WriteOptions.
+type WriteConfig struct {
+	// Number of series sent in single write request.
+	BatchSize int `toml:"batch-size" yaml:"batch-size"`
+	// Flush interval which is buffer flushed if it has not been already written.
+	FlushInterval ltoml.Duration `toml:"flush-interval" yaml:"flush-interval"`
+	// Whether to use GZip compress write data.
+	UseGZip bool `toml:"gzip" yaml:"gzip"`
+	// Default tags are added to each written series.
+	DefaultTags map[string]string `toml:"default-tags" yaml:"default-tags"`
+	// Maximum count of retry attempts of failed writes.
+	MaxRetries int `toml:"max-retries" yaml:"max-retries"`
+	// Maximum number of write request to keep for retry.
+	RetryBufferLimit int `toml:"retry-buffer-limit" yaml:"retry-buffer-limit"`
+	// Maximum number of write request to hold while write client paused.
+	PauseBufferLimit int `toml:"pause-buffer-limit" yaml:"pause-buffer-limit"`
+	// Maximum number of points sent per second, 0 means no limit.
+	PointsPerSecond int `toml:"points-per-second" yaml:"points-per-second"`
+	// Maximum number of request bytes sent per second, 0 means no limit.
+	BytesPerSecond int `toml:"bytes-per-second" yaml:"bytes-per-second"`
+	// Behaviour when rate limit is hit(delay/drop).
+	RateLimitMode string `toml:"rate-limit-mode" yaml:"rate-limit-mode"`
+	// Expiry of idle series state for converting cumulative sum into delta.
+	CumulativeExpiry ltoml.Duration `toml:"cumulative-expiry" yaml:"cumulative-expiry"`
+	// Time slot of merging points with same series before encoding, 0 means disabled.
+	AggregateInterval ltoml.Duration `toml:"aggregate-interval" yaml:"aggregate-interval"`
+}
+
+// HTTPConfig represents the configuration of HTTP client, maps to http.Options.
+type HTTPConfig struct {
+	// Request timeout.
+	Timeout ltoml.Duration `toml:"timeout" yaml:"timeout"`
+	// Dial timeout of establishing connection.
+	DialTimeout ltoml.Duration `toml:"dial-timeout" yaml:"dial-timeout"`
+	// Keep-alive period of active connection.
+	KeepAlive ltoml.Duration `toml:"keep-alive" yaml:"keep-alive"`
+	// TLS handshake timeout.
+	TLSHandshakeTimeout ltoml.Duration `toml:"tls-handshake-timeout" yaml:"tls-handshake-timeout"`
+	// Maximum number of idle connections across all hosts.
+	MaxIdleConns int `toml:"max-idle-conns" yaml:"max-idle-conns"`
+	// Maximum number of idle connections per host.
+	MaxIdleConnsPerHost int `toml:"max-idle-conns-per-host" yaml:"max-idle-conns-per-host"`
+	// Maximum number of connections per host, 0 means no limit.
+	MaxConnsPerHost int `toml:"max-conns-per-host" yaml:"max-conns-per-host"`
+	// Idle connection timeout.
+	IdleConnTimeout ltoml.Duration `toml:"idle-conn-timeout" yaml:"idle-conn-timeout"`
+	// Whether to attempt HTTP/2 for connection.
+	EnableHTTP2 bool `toml:"http2" yaml:"http2"`
+	// Proxy url for request, empty means no proxy.
+	Proxy string `toml:"proxy" yaml:"proxy"`
+	// Username/password of basic auth.
+	Username string `toml:"username" yaml:"username"`
+	Password string `toml:"password" yaml:"password"`
+	// Bearer token, cannot be used with basic auth.
+	Token string `toml:"token" yaml:"token"`
+	// Static headers added to each request.
+	Headers map[string]string `toml:"headers" yaml:"headers"`
+	// Consecutive failures of endpoint to open circuit breaker, 0 means disabled.
+	CircuitFailureThreshold int `toml:"circuit-failure-threshold" yaml:"circuit-failure-threshold"`
+	// Cool-down of open circuit breaker before probing endpoint.
+	CircuitCoolDown ltoml.Duration `toml:"circuit-cool-down" yaml:"circuit-cool-down"`
+	// TLS options.
+	TLS TLSConfig `toml:"tls" yaml:"tls"`
+}
+
+// TLSConfig represents the TLS configuration of HTTP client.
+type TLSConfig struct {
+	// CA certificate file for verifying server certificate.
+	CAFile string `toml:"ca-file" yaml:"ca-file"`
+	// Client certificate/key file for mutual TLS.
+	CertFile string `toml:"cert-file" yaml:"cert-file"`
+	KeyFile  string `toml:"key-file" yaml:"key-file"`
+	// Server name for verifying server certificate.
+	ServerName string `toml:"server-name" yaml:"server-name"`
+	// Minimum TLS version(1.0/1.1/1.2/1.3).
+	MinVersion string `toml:"min-version" yaml:"min-version"`
+	// Whether to skip verifying server certificate.
+	InsecureSkipVerify bool `toml:"insecure-skip-verify" yaml:"insecure-skip-verify"`
+}
+
+// DefaultConfig returns the config with default options.
+func DefaultConfig() *Config {
+	opt := DefaultOptions()
+	writeOpt := opt.WriteOptions()
+	httpOpt := opt.HTTPOptions()
+	_, circuitCoolDown := httpOpt.CircuitBreaker()
+	return &Config{
+		DiscoveryInterval: seconds(opt.DiscoveryInterval()),
+		Write: WriteConfig{
+			BatchSize:        writeOpt.BatchSize(),
+			FlushInterval:    ltoml.Duration(time.Duration(writeOpt.FlushInterval()) * time.Millisecond),
+			UseGZip:          writeOpt.UseGZip(),
+			MaxRetries:       writeOpt.MaxRetries(),
+			RetryBufferLimit: writeOpt.RetryBufferLimit(),
+			PauseBufferLimit: writeOpt.PauseBufferLimit(),
+			RateLimitMode:    writeOpt.RateLimitMode().String(),
+			CumulativeExpiry: ltoml.Duration(time.Duration(writeOpt.CumulativeExpiry()) * time.Millisecond),
+		},
+		HTTP: HTTPConfig{
+			Timeout:             seconds(httpOpt.ReqTimeout()),
+			DialTimeout:         seconds(httpOpt.DialTimeout()),
+			KeepAlive:           seconds(httpOpt.KeepAlive()),
+			TLSHandshakeTimeout: seconds(httpOpt.TLSHandshakeTimeout()),
+			MaxIdleConns:        httpOpt.MaxIdleConns(),
+			MaxIdleConnsPerHost: httpOpt.MaxIdleConnsPerHost(),
+			MaxConnsPerHost:     httpOpt.MaxConnsPerHost(),
+			IdleConnTimeout:     seconds(httpOpt.IdleConnTimeout()),
+			EnableHTTP2:         httpOpt.EnableHTTP2(),
+			CircuitCoolDown:     seconds(circuitCoolDown),
+		},
+	}
+}
+
+// LoadConfig loads config from TOML(.toml) or YAML(.yaml/.yml) file based on default config,
+// then overrides config by LINDB_* environment variables, file is skipped if path is empty.
+// Environment variable name is upper-cased key path with '.'/'-' replaced by '_',
+// e.g. LINDB_ENDPOINTS=http://broker1:9000,http://broker2:9000, LINDB_WRITE_BATCH_SIZE=500,
+// LINDB_WRITE_DEFAULT_TAGS=host=host1,region=sh(merged into tags of file).
+func LoadConfig(path string) (*Config, error) {
+	cfg := DefaultConfig()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		switch ext := strings.ToLower(filepath.Ext(path)); ext {
+		case ".toml":
+			err = decodeTOML(data, cfg)
+		case ".yaml", ".yml":
+			err = decodeYAML(data, cfg)
+		default:
+			err = fmt.Errorf("unsupported config file format: %q", ext)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load config file %q failure: %w", path, err)
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), ""); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// NewClientFromConfig creates a Client with endpoints and options of config.
+func NewClientFromConfig(cfg *Config) (Client, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, configError("endpoints", "at least one endpoint is required")
+	}
+	options, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	c, err := newValidClient(cfg.Endpoints, options)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Options validates config, then converts config into Options.
+func (c *Config) Options() (*Options, error) {
+	opt := DefaultOptions().SetDatabase(c.Database).SetDiscovery(c.Discovery)
+	for _, endpoint := range c.Endpoints {
+		if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, configError("endpoints", "invalid endpoint %q", endpoint)
+		}
+	}
+	interval, err := toSeconds("discovery-interval", c.DiscoveryInterval)
+	if err != nil {
+		return nil, err
+	}
+	opt.SetDiscoveryInterval(interval)
+	if err := c.Write.apply(opt); err != nil {
+		return nil, err
+	}
+	if err := c.HTTP.apply(opt); err != nil {
+		return nil, err
+	}
+	return opt, nil
+}
+
+// apply validates write config, then applies write config to options.
+func (c *WriteConfig) apply(opt *Options) error {
+	if c.BatchSize <= 0 {
+		return configError("write.batch-size", "must be positive, got %d", c.BatchSize)
+	}
+	if c.FlushInterval.Duration() < time.Millisecond || c.FlushInterval.Duration()%time.Millisecond != 0 {
+		return configError("write.flush-interval", "must be positive multiple of 1ms, got %s", c.FlushInterval)
+	}
+	if c.MaxRetries < 0 {
+		return configError("write.max-retries", "must not be negative, got %d", c.MaxRetries)
+	}
+	if c.RetryBufferLimit < 0 {
+		return configError("write.retry-buffer-limit", "must not be negative, got %d", c.RetryBufferLimit)
+	}
+	if c.PauseBufferLimit < 0 {
+		return configError("write.pause-buffer-limit", "must not be negative, got %d", c.PauseBufferLimit)
+	}
+	if c.PointsPerSecond < 0 {
+		return configError("write.points-per-second", "must not be negative, got %d", c.PointsPerSecond)
+	}
+	if c.BytesPerSecond < 0 {
+		return configError("write.bytes-per-second", "must not be negative, got %d", c.BytesPerSecond)
+	}
+	mode, err := api.ParseRateLimitMode(c.RateLimitMode)
+	if err != nil {
+		return configError("write.rate-limit-mode", "%s", err)
+	}
+	if c.CumulativeExpiry.Duration() < time.Millisecond || c.CumulativeExpiry.Duration()%time.Millisecond != 0 {
+		return configError("write.cumulative-expiry", "must be positive multiple of 1ms, got %s", c.CumulativeExpiry)
+	}
+	if c.AggregateInterval.Duration() < 0 || c.AggregateInterval.Duration()%time.Millisecond != 0 {
+		return configError("write.aggregate-interval", "must be non-negative multiple of 1ms, got %s", c.AggregateInterval)
+	}
+	for key := range c.DefaultTags {
+		if key == "" {
+			return configError("write.default-tags", "tag key must not be empty")
+		}
+	}
+	opt.SetBatchSize(c.BatchSize).
+		SetFlushInterval(c.FlushInterval.Duration().Milliseconds()).
+		SetUseGZip(c.UseGZip).
+		SetMaxRetries(c.MaxRetries).
+		SetRetryBufferLimit(c.RetryBufferLimit).
+		SetPauseBufferLimit(c.PauseBufferLimit).
+		SetPointsPerSecond(c.PointsPerSecond).
+		SetBytesPerSecond(c.BytesPerSecond).
+		SetRateLimitMode(mode).
+		SetCumulativeExpiry(c.CumulativeExpiry.Duration().Milliseconds()).
+		SetAggregateInterval(c.AggregateInterval.Duration().Milliseconds())
+	for key, value := range c.DefaultTags {
+		opt.AddDefaultTag(key, value)
+	}
+	return nil
+}
+
+// apply validates http config, then applies http config to options.
+func (c *HTTPConfig) apply(opt *Options) error {
+	durations := []struct {
+		key      string
+		duration ltoml.Duration
+		positive bool
+		set      func(v int64) *Options
+	}{
+		{key: "http.timeout", duration: c.Timeout, positive: true, set: opt.SetReqTimeout},
+		{key: "http.dial-timeout", duration: c.DialTimeout, set: opt.SetDialTimeout},
+		{key: "http.keep-alive", duration: c.KeepAlive, set: opt.SetKeepAlive},
+		{key: "http.tls-handshake-timeout", duration: c.TLSHandshakeTimeout, set: opt.SetTLSHandshakeTimeout},
+		{key: "http.idle-conn-timeout", duration: c.IdleConnTimeout, set: opt.SetIdleConnTimeout},
+	}
+	for _, d := range durations {
+		v, err := toSeconds(d.key, d.duration)
+		if err != nil {
+			return err
+		}
+		if d.positive && v == 0 {
+			return configError(d.key, "must be positive")
+		}
+		d.set(v)
+	}
+	conns := []struct {
+		key   string
+		value int
+		set   func(v int) *Options
+	}{
+		{key: "http.max-idle-conns", value: c.MaxIdleConns, set: opt.SetMaxIdleConns},
+		{key: "http.max-idle-conns-per-host", value: c.MaxIdleConnsPerHost, set: opt.SetMaxIdleConnsPerHost},
+		{key: "http.max-conns-per-host", value: c.MaxConnsPerHost, set: opt.SetMaxConnsPerHost},
+	}
+	for _, conn := range conns {
+		if conn.value < 0 {
+			return configError(conn.key, "must not be negative, got %d", conn.value)
+		}
+		conn.set(conn.value)
+	}
+	opt.SetEnableHTTP2(c.EnableHTTP2)
+	if c.CircuitFailureThreshold < 0 {
+		return configError("http.circuit-failure-threshold", "must not be negative, got %d", c.CircuitFailureThreshold)
+	}
+	coolDown, err := toSeconds("http.circuit-cool-down", c.CircuitCoolDown)
+	if err != nil {
+		return err
+	}
+	if c.CircuitFailureThreshold > 0 && coolDown == 0 {
+		return configError("http.circuit-cool-down", "must be positive if circuit breaker enabled")
+	}
+	opt.SetCircuitBreaker(c.CircuitFailureThreshold, coolDown)
+	if c.Proxy != "" {
+		proxyURL, err := url.Parse(c.Proxy)
+		if err != nil {
+			return configError("http.proxy", "%s", err)
+		}
+		opt.SetProxy(nethttp.ProxyURL(proxyURL))
+	}
+	switch {
+	case c.Token != "" && (c.Username != "" || c.Password != ""):
+		return configError("http.token", "cannot be used with username/password")
+	case c.Token != "":
+		opt.SetCredentialsProvider(NewBearerToken(c.Token))
+	case c.Username != "":
+		opt.SetCredentialsProvider(NewBasicAuth(c.Username, c.Password))
+	case c.Password != "":
+		return configError("http.username", "required if password is set")
+	}
+	for key, value := range c.Headers {
+		if key == "" {
+			return configError("http.headers", "header key must not be empty")
+		}
+		opt.AddHeader(key, value)
+	}
+	return c.TLS.apply(opt)
+}
+
+// apply validates tls config, then applies tls config to options.
+func (c *TLSConfig) apply(opt *Options) error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return configError("http.tls.cert-file", "cert-file and key-file must be set together")
+	}
+	if c.MinVersion != "" {
+		version, err := parseTLSVersion(c.MinVersion)
+		if err != nil {
+			return configError("http.tls.min-version", "%s, got %q", err, c.MinVersion)
+		}
+		opt.SetMinTLSVersion(version)
+	}
+	if c.InsecureSkipVerify {
+		opt.SetTLSConfig(&tls.Config{InsecureSkipVerify: true}) //nolint:gosec
+	}
+	opt.SetCAFile(c.CAFile).SetClientCertFile(c.CertFile, c.KeyFile).SetServerName(c.ServerName)
+	return nil
+}
+
+// decodeTOML decodes TOML data into config, returns error if config contains unknown key.
+func decodeTOML(data []byte, cfg *Config) error {
+	meta, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		return err
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("unknown config key: %q", undecoded[0].String())
+	}
+	return nil
+}
+
+// decodeYAML decodes YAML data into config, returns error if config contains unknown key.
+func decodeYAML(data []byte, cfg *Config) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// applyEnv overrides struct fields by environment variables, key is the path of TOML key.
+func applyEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("toml")
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field, key); err != nil {
+				return err
+			}
+			continue
+		}
+		name := envPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setField(field, value); err != nil {
+			return configError(key, "invalid environment variable %s=%q: %s", name, value, err)
+		}
+	}
+	return nil
+}
+
+// setField sets field by string value, list/map value is separated by comma, map entry is key=value.
+func setField(field reflect.Value, value string) error {
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
+	case reflect.Int:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(v))
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		if field.IsNil() {
+			field.Set(reflect.ValueOf(map[string]string{}))
+		}
+		for _, entry := range strings.Split(value, ",") {
+			k, v, ok := strings.Cut(entry, "=")
+			if !ok {
+				return fmt.Errorf("expect key=value entry, got %q", entry)
+			}
+			field.SetMapIndex(reflect.ValueOf(strings.TrimSpace(k)), reflect.ValueOf(strings.TrimSpace(v)))
+		}
+	default:
+		return fmt.Errorf("unsupported type: %s", field.Type())
+	}
+	return nil
+}
+
+// toSeconds converts duration into seconds, returns error if duration is negative or not multiple of 1s.
+func toSeconds(key string, d ltoml.Duration) (int64, error) {
+	if d < 0 || d.Duration()%time.Second != 0 {
+		return 0, configError(key, "must be non-negative multiple of 1s, got %s", d)
+	}
+	return int64(d.Duration() / time.Second), nil
+}
+
+// seconds returns the duration of given seconds.
+func seconds(sec int64) ltoml.Duration {
+	return ltoml.Duration(time.Duration(sec) * time.Second)
+}
+
+// configError returns the error of invalid config with key.
+func configError(key, format string, args ...interface{}) error {
+	return fmt.Errorf("invalid config %q: %s", key, fmt.Sprintf(format, args...))
+}
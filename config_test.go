@@ -0,0 +1,284 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lindb
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/api"
+)
+
+const tomlConfig = `
+endpoints = ["http://broker1:9000", "http://broker2:9000"]
+database = "mydb"
+discovery = true
+discovery-interval = "10s"
+
+[write]
+batch-size = 500
+flush-interval = "2s"
+gzip = false
+max-retries = 5
+retry-buffer-limit = 10
+pause-buffer-limit = 20
+points-per-second = 100
+rate-limit-mode = "drop"
+cumulative-expiry = "10m"
+aggregate-interval = "10s"
+[write.default-tags]
+host = "host1"
+
+[http]
+timeout = "1m"
+circuit-failure-threshold = 3
+circuit-cool-down = "1m"
+max-conns-per-host = 8
+token = "abc"
+[http.headers]
+X-Tenant = "t1"
+[http.tls]
+ca-file = "ca.pem"
+cert-file = "cert.pem"
+key-file = "key.pem"
+min-version = "1.3"
+`
+
+const yamlConfig = `
+endpoints:
+  - http://broker1:9000
+  - http://broker2:9000
+database: mydb
+discovery: true
+discovery-interval: 10s
+write:
+  batch-size: 500
+  flush-interval: 2s
+  gzip: false
+  max-retries: 5
+  retry-buffer-limit: 10
+  pause-buffer-limit: 20
+  points-per-second: 100
+  rate-limit-mode: drop
+  cumulative-expiry: 10m
+  aggregate-interval: 10s
+  default-tags:
+    host: host1
+http:
+  timeout: 1m
+  circuit-failure-threshold: 3
+  circuit-cool-down: 1m
+  max-conns-per-host: 8
+  token: abc
+  headers:
+    X-Tenant: t1
+  tls:
+    ca-file: ca.pem
+    cert-file: cert.pem
+    key-file: key.pem
+    min-version: "1.3"
+`
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	for name, content := range map[string]string{
+		"lindb.toml": tomlConfig,
+		"lindb.yaml": yamlConfig,
+		"lindb.yml":  yamlConfig,
+	} {
+		name, content := name, content
+		t.Run(name, func(t *testing.T) {
+			cfg, err := LoadConfig(writeConfigFile(t, name, content))
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"http://broker1:9000", "http://broker2:9000"}, cfg.Endpoints)
+			opt, err := cfg.Options()
+			assert.NoError(t, err)
+			assert.Equal(t, "mydb", opt.Database())
+			assert.True(t, opt.Discovery())
+			assert.Equal(t, int64(10), opt.DiscoveryInterval())
+			assert.Equal(t, 500, opt.WriteOptions().BatchSize())
+			assert.Equal(t, int64(2_000), opt.WriteOptions().FlushInterval())
+			assert.False(t, opt.WriteOptions().UseGZip())
+			assert.Equal(t, 5, opt.WriteOptions().MaxRetries())
+			assert.Equal(t, 10, opt.WriteOptions().RetryBufferLimit())
+			assert.Equal(t, 20, opt.WriteOptions().PauseBufferLimit())
+			assert.Equal(t, 100, opt.WriteOptions().PointsPerSecond())
+			assert.Equal(t, api.RateLimitDrop, opt.WriteOptions().RateLimitMode())
+			assert.Equal(t, int64(600_000), opt.WriteOptions().CumulativeExpiry())
+			assert.Equal(t, int64(10_000), opt.WriteOptions().AggregateInterval())
+			assert.Equal(t, map[string]string{"host": "host1"}, opt.WriteOptions().DefaultTags())
+			httpOpt := opt.HTTPOptions()
+			assert.Equal(t, int64(60), httpOpt.ReqTimeout())
+			failureThreshold, coolDown := httpOpt.CircuitBreaker()
+			assert.Equal(t, 3, failureThreshold)
+			assert.Equal(t, int64(60), coolDown)
+			// defaults kept
+			assert.Equal(t, int64(5), httpOpt.DialTimeout())
+			assert.Equal(t, 100, httpOpt.MaxIdleConns())
+			assert.Equal(t, 8, httpOpt.MaxConnsPerHost())
+			assert.Equal(t, NewBearerToken("abc"), httpOpt.CredentialsProvider())
+			assert.Equal(t, map[string]string{"X-Tenant": "t1"}, httpOpt.Headers())
+			assert.Equal(t, "ca.pem", httpOpt.CAFile())
+			certFile, keyFile := httpOpt.ClientCertFile()
+			assert.Equal(t, "cert.pem", certFile)
+			assert.Equal(t, "key.pem", keyFile)
+			assert.Equal(t, uint16(tls.VersionTLS13), httpOpt.MinTLSVersion())
+		})
+	}
+}
+
+func TestLoadConfig_Default(t *testing.T) {
+	cfg, err := LoadConfig("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), cfg)
+	opt, err := cfg.Options()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultOptions(), opt)
+}
+
+func TestLoadConfig_Env(t *testing.T) {
+	t.Setenv("LINDB_ENDPOINTS", "http://broker3:9000, http://broker4:9000")
+	t.Setenv("LINDB_DATABASE", "envdb")
+	t.Setenv("LINDB_WRITE_BATCH_SIZE", "100")
+	t.Setenv("LINDB_WRITE_GZIP", "true")
+	t.Setenv("LINDB_WRITE_DEFAULT_TAGS", "region=sh, zone=a")
+	t.Setenv("LINDB_HTTP_TIMEOUT", "5s")
+	t.Setenv("LINDB_HTTP_TLS_SERVER_NAME", "lindb.io")
+	t.Setenv("LINDB_HTTP_TLS_INSECURE_SKIP_VERIFY", "true")
+
+	cfg, err := LoadConfig(writeConfigFile(t, "lindb.toml", tomlConfig))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://broker3:9000", "http://broker4:9000"}, cfg.Endpoints)
+	opt, err := cfg.Options()
+	assert.NoError(t, err)
+	assert.Equal(t, "envdb", opt.Database())
+	assert.Equal(t, 100, opt.WriteOptions().BatchSize())
+	assert.True(t, opt.WriteOptions().UseGZip())
+	assert.Equal(t, map[string]string{"host": "host1", "region": "sh", "zone": "a"}, opt.WriteOptions().DefaultTags())
+	assert.Equal(t, int64(5), opt.HTTPOptions().ReqTimeout())
+	assert.Equal(t, "lindb.io", opt.HTTPOptions().ServerName())
+	assert.True(t, opt.HTTPOptions().TLSConfig().InsecureSkipVerify)
+}
+
+func TestLoadConfig_Failure(t *testing.T) {
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "not_exist.toml"))
+	assert.Error(t, err)
+	_, err = LoadConfig(writeConfigFile(t, "lindb.json", "{}"))
+	assert.ErrorContains(t, err, "unsupported config file format")
+	_, err = LoadConfig(writeConfigFile(t, "lindb.toml", "[write]\nbatch-sizes = 1"))
+	assert.ErrorContains(t, err, `unknown config key: "write.batch-sizes"`)
+	_, err = LoadConfig(writeConfigFile(t, "lindb.toml", "[write]\nbatch-size = \"abc\""))
+	assert.Error(t, err)
+	_, err = LoadConfig(writeConfigFile(t, "lindb.yaml", "write:\n  batch-sizes: 1"))
+	assert.ErrorContains(t, err, "batch-sizes")
+	_, err = LoadConfig(writeConfigFile(t, "lindb.yaml", "http:\n  timeout: abc"))
+	assert.Error(t, err)
+
+	cases := []struct {
+		env   string
+		value string
+		key   string
+	}{
+		{env: "LINDB_WRITE_BATCH_SIZE", value: "abc", key: "write.batch-size"},
+		{env: "LINDB_DISCOVERY", value: "abc", key: "discovery"},
+		{env: "LINDB_HTTP_TIMEOUT", value: "abc", key: "http.timeout"},
+		{env: "LINDB_HTTP_HEADERS", value: "abc", key: "http.headers"},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			_, err := LoadConfig("")
+			assert.ErrorContains(t, err, `invalid config "`+tt.key+`"`)
+		})
+	}
+}
+
+func TestConfig_Options_Failure(t *testing.T) {
+	cases := []struct {
+		key    string
+		modify func(cfg *Config)
+	}{
+		{key: "endpoints", modify: func(cfg *Config) { cfg.Endpoints = []string{"broker1"} }},
+		{key: "discovery-interval", modify: func(cfg *Config) { cfg.DiscoveryInterval = -1 }},
+		{key: "write.batch-size", modify: func(cfg *Config) { cfg.Write.BatchSize = 0 }},
+		{key: "write.flush-interval", modify: func(cfg *Config) { cfg.Write.FlushInterval = 0 }},
+		{key: "write.max-retries", modify: func(cfg *Config) { cfg.Write.MaxRetries = -1 }},
+		{key: "write.retry-buffer-limit", modify: func(cfg *Config) { cfg.Write.RetryBufferLimit = -1 }},
+		{key: "write.pause-buffer-limit", modify: func(cfg *Config) { cfg.Write.PauseBufferLimit = -1 }},
+		{key: "write.points-per-second", modify: func(cfg *Config) { cfg.Write.PointsPerSecond = -1 }},
+		{key: "write.bytes-per-second", modify: func(cfg *Config) { cfg.Write.BytesPerSecond = -1 }},
+		{key: "write.rate-limit-mode", modify: func(cfg *Config) { cfg.Write.RateLimitMode = "block" }},
+		{key: "write.cumulative-expiry", modify: func(cfg *Config) { cfg.Write.CumulativeExpiry = 0 }},
+		{key: "write.aggregate-interval", modify: func(cfg *Config) { cfg.Write.AggregateInterval = -1 }},
+		{key: "write.default-tags", modify: func(cfg *Config) { cfg.Write.DefaultTags = map[string]string{"": "v"} }},
+		{key: "http.timeout", modify: func(cfg *Config) { cfg.HTTP.Timeout = 0 }},
+		{key: "http.dial-timeout", modify: func(cfg *Config) { cfg.HTTP.DialTimeout = seconds(1) + 1 }},
+		{key: "http.circuit-failure-threshold", modify: func(cfg *Config) { cfg.HTTP.CircuitFailureThreshold = -1 }},
+		{key: "http.circuit-cool-down", modify: func(cfg *Config) { cfg.HTTP.CircuitCoolDown = seconds(1) + 1 }},
+		{key: "http.circuit-cool-down", modify: func(cfg *Config) {
+			cfg.HTTP.CircuitFailureThreshold, cfg.HTTP.CircuitCoolDown = 3, 0
+		}},
+		{key: "http.max-idle-conns", modify: func(cfg *Config) { cfg.HTTP.MaxIdleConns = -1 }},
+		{key: "http.proxy", modify: func(cfg *Config) { cfg.HTTP.Proxy = "://abc" }},
+		{key: "http.token", modify: func(cfg *Config) { cfg.HTTP.Token, cfg.HTTP.Username = "abc", "admin" }},
+		{key: "http.username", modify: func(cfg *Config) { cfg.HTTP.Password = "pwd" }},
+		{key: "http.headers", modify: func(cfg *Config) { cfg.HTTP.Headers = map[string]string{"": "v"} }},
+		{key: "http.tls.cert-file", modify: func(cfg *Config) { cfg.HTTP.TLS.CertFile = "cert.pem" }},
+		{key: "http.tls.min-version", modify: func(cfg *Config) { cfg.HTTP.TLS.MinVersion = "2.0" }},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.key, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Endpoints = []string{"http://broker1:9000"}
+			tt.modify(cfg)
+			opt, err := cfg.Options()
+			assert.ErrorContains(t, err, `invalid config "`+tt.key+`"`)
+			assert.Nil(t, opt)
+			c, err := NewClientFromConfig(cfg)
+			assert.Error(t, err)
+			assert.Nil(t, c)
+		})
+	}
+}
+
+func TestNewClientFromConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	c, err := NewClientFromConfig(cfg)
+	assert.ErrorContains(t, err, `invalid config "endpoints"`)
+	assert.Nil(t, c)
+
+	cfg.Endpoints = []string{"http://broker1:9000", "http://broker2:9000"}
+	cfg.HTTP.Username, cfg.HTTP.Password = "admin", "pwd"
+	cfg.HTTP.Proxy = "http://proxy:8080"
+	c, err = NewClientFromConfig(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, cfg.Endpoints, c.(*client).resolver.Endpoints())
+	assert.Equal(t, NewBasicAuth("admin", "pwd"), c.(*client).httpOptions.CredentialsProvider())
+	assert.NotNil(t, c.(*client).httpOptions.Proxy())
+}
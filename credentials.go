@@ -0,0 +1,43 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lindb
+
+import (
+	"context"
+
+	"github.com/lindb/client_go/internal/http"
+)
+
+// CredentialsProvider represents the credentials provider for authenticating write/query request.
+type CredentialsProvider = http.CredentialsProvider
+
+// NewBasicAuth creates a CredentialsProvider with static username/password.
+func NewBasicAuth(username, password string) CredentialsProvider {
+	return http.NewBasicAuth(username, password)
+}
+
+// NewBearerToken creates a CredentialsProvider with static bearer token.
+func NewBearerToken(token string) CredentialsProvider {
+	return http.NewBearerToken(token)
+}
+
+// NewRefreshingToken creates a CredentialsProvider with bearer token fetched by fetcher,
+// token is re-fetched after request unauthorized(401).
+func NewRefreshingToken(fetcher func(ctx context.Context) (string, error)) CredentialsProvider {
+	return http.NewRefreshingToken(fetcher)
+}
@@ -0,0 +1,57 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lindb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/api"
+)
+
+func TestCredentials(t *testing.T) {
+	var authorized atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "admin" || password != "admin123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		authorized.Add(1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer svr.Close()
+
+	cli := NewClientWithOptions(svr.URL, DefaultOptions().SetCredentialsProvider(NewBasicAuth("admin", "admin123")))
+	_, err := cli.DataQuery().MetadataQuery(context.TODO(), "test", "show fields from cpu")
+	assert.NoError(t, err)
+	w := cli.Write("test")
+	w.AddPoint(context.TODO(), api.NewPoint("cpu").AddField(api.NewSum("load", 1.0)))
+	assert.NoError(t, cli.Close(context.TODO()))
+	assert.Equal(t, int32(2), authorized.Load())
+
+	assert.NotNil(t, NewBearerToken("token"))
+	assert.NotNil(t, NewRefreshingToken(func(_ context.Context) (string, error) {
+		return "token", nil
+	}))
+}
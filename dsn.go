@@ -0,0 +1,311 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lindb
+
+import (
+	"crypto/tls"
+	"fmt"
+	nethttp "net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lindb/client_go/api"
+)
+
+const (
+	// defaultBrokerPort represents the default HTTP port of broker if port not specified in DSN.
+	defaultBrokerPort = "9000"
+	// tagParamPrefix represents the DSN parameter prefix of default tag, e.g. tag.host=host1.
+	tagParamPrefix = "tag."
+	// headerParamPrefix represents the DSN parameter prefix of static header, e.g. header.X-Tenant=t1.
+	headerParamPrefix = "header."
+)
+
+// dsnParams represents the supported DSN parameters, value is the setter applied to options.
+var dsnParams = map[string]func(o *Options, value string) error{
+	// write options
+	"batchSize":         intParam(func(o *Options, v int) { o.SetBatchSize(v) }),
+	"flushInterval":     durationParam(time.Millisecond, func(o *Options, v int64) { o.SetFlushInterval(v) }),
+	"gzip":              boolParam(func(o *Options, v bool) { o.SetUseGZip(v) }),
+	"maxRetries":        intParam(func(o *Options, v int) { o.SetMaxRetries(v) }),
+	"retryBufferLimit":  intParam(func(o *Options, v int) { o.SetRetryBufferLimit(v) }),
+	"pauseBufferLimit":  intParam(func(o *Options, v int) { o.SetPauseBufferLimit(v) }),
+	"pointsPerSecond":   intParam(func(o *Options, v int) { o.SetPointsPerSecond(v) }),
+	"bytesPerSecond":    intParam(func(o *Options, v int) { o.SetBytesPerSecond(v) }),
+	"cumulativeExpiry":  durationParam(time.Millisecond, func(o *Options, v int64) { o.SetCumulativeExpiry(v) }),
+	"aggregateInterval": durationParam(time.Millisecond, func(o *Options, v int64) { o.SetAggregateInterval(v) }),
+	"rateLimitMode": func(o *Options, value string) error {
+		mode, err := api.ParseRateLimitMode(value)
+		if err != nil {
+			return err
+		}
+		o.SetRateLimitMode(mode)
+		return nil
+	},
+	// http options
+	"timeout":             durationParam(time.Second, func(o *Options, v int64) { o.SetReqTimeout(v) }),
+	"dialTimeout":         durationParam(time.Second, func(o *Options, v int64) { o.SetDialTimeout(v) }),
+	"keepAlive":           durationParam(time.Second, func(o *Options, v int64) { o.SetKeepAlive(v) }),
+	"tlsHandshakeTimeout": durationParam(time.Second, func(o *Options, v int64) { o.SetTLSHandshakeTimeout(v) }),
+	"idleConnTimeout":     durationParam(time.Second, func(o *Options, v int64) { o.SetIdleConnTimeout(v) }),
+	"maxIdleConns":        intParam(func(o *Options, v int) { o.SetMaxIdleConns(v) }),
+	"maxIdleConnsPerHost": intParam(func(o *Options, v int) { o.SetMaxIdleConnsPerHost(v) }),
+	"maxConnsPerHost":     intParam(func(o *Options, v int) { o.SetMaxConnsPerHost(v) }),
+	"http2":               boolParam(func(o *Options, v bool) { o.SetEnableHTTP2(v) }),
+	"circuitFailureThreshold": intParam(func(o *Options, v int) {
+		_, coolDown := o.HTTPOptions().CircuitBreaker()
+		o.SetCircuitBreaker(v, coolDown)
+	}),
+	"circuitCoolDown": durationParam(time.Second, func(o *Options, v int64) {
+		failureThreshold, _ := o.HTTPOptions().CircuitBreaker()
+		o.SetCircuitBreaker(failureThreshold, v)
+	}),
+	"proxy": func(o *Options, value string) error {
+		proxyURL, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		o.SetProxy(nethttp.ProxyURL(proxyURL))
+		return nil
+	},
+	"token": func(o *Options, value string) error {
+		o.SetCredentialsProvider(NewBearerToken(value))
+		return nil
+	},
+	// tls options
+	"caFile":     stringParam(func(o *Options, v string) { o.SetCAFile(v) }),
+	"serverName": stringParam(func(o *Options, v string) { o.SetServerName(v) }),
+	"certFile": stringParam(func(o *Options, v string) {
+		_, keyFile := o.HTTPOptions().ClientCertFile()
+		o.SetClientCertFile(v, keyFile)
+	}),
+	"keyFile": stringParam(func(o *Options, v string) {
+		certFile, _ := o.HTTPOptions().ClientCertFile()
+		o.SetClientCertFile(certFile, v)
+	}),
+	"minTLSVersion": func(o *Options, value string) error {
+		version, err := parseTLSVersion(value)
+		if err != nil {
+			return err
+		}
+		o.SetMinTLSVersion(version)
+		return nil
+	},
+	"insecureSkipVerify": boolParam(func(o *Options, v bool) {
+		cfg := o.HTTPOptions().TLSConfig()
+		if cfg == nil {
+			cfg = &tls.Config{}
+		} else {
+			cfg = cfg.Clone()
+		}
+		cfg.InsecureSkipVerify = v
+		o.SetTLSConfig(cfg)
+	}),
+	// discovery options
+	"discovery":         boolParam(func(o *Options, v bool) { o.SetDiscovery(v) }),
+	"discoveryInterval": durationParam(time.Second, func(o *Options, v int64) { o.SetDiscoveryInterval(v) }),
+}
+
+// NewClientFromDSN creates a Client with data source name(DSN), format:
+//
+//	lindb[+http|+https]://[username:password@]host1[:port1][,host2[:port2]...][/database][?param1=value1&...]
+//
+// e.g. lindb+https://broker1,broker2:9000/mydb?batchSize=500&flushInterval=2s&gzip=true&timeout=30s,
+// port is 9000 if not specified, see ParseDSN for supported parameters.
+func NewClientFromDSN(dsn string) (Client, error) {
+	endpoints, options, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newValidClient(endpoints, options)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ParseDSN parses data source name(DSN), returns broker endpoints and options.
+// Supported parameters:
+//   - write: batchSize, flushInterval(duration), gzip, maxRetries, retryBufferLimit, pauseBufferLimit,
+//     pointsPerSecond, bytesPerSecond, rateLimitMode(delay/drop), cumulativeExpiry(duration),
+//     aggregateInterval(duration), tag.<key>=<value>
+//   - http: timeout, dialTimeout, keepAlive, tlsHandshakeTimeout, idleConnTimeout(duration),
+//     maxIdleConns, maxIdleConnsPerHost, maxConnsPerHost, http2, proxy, token, header.<key>=<value>,
+//     circuitFailureThreshold, circuitCoolDown(duration)
+//   - tls: caFile, certFile, keyFile, serverName, minTLSVersion(1.0-1.3), insecureSkipVerify
+//   - discovery: discovery, discoveryInterval(duration)
+func ParseDSN(dsn string) (endpoints []string, options *Options, err error) {
+	scheme, rest, ok := strings.Cut(dsn, "://")
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid dsn, missing scheme: %q", dsn)
+	}
+	switch scheme {
+	case "lindb", "lindb+http":
+		scheme = "http"
+	case "lindb+https":
+		scheme = "https"
+	default:
+		return nil, nil, fmt.Errorf("invalid dsn, unsupported scheme: %q", scheme)
+	}
+	// split authority and path/query
+	authority, pathQuery := rest, ""
+	if idx := strings.IndexAny(rest, "/?"); idx >= 0 {
+		authority, pathQuery = rest[:idx], rest[idx:]
+	}
+	options = DefaultOptions()
+	if idx := strings.LastIndex(authority, "@"); idx >= 0 {
+		if err := parseUserInfo(options, authority[:idx]); err != nil {
+			return nil, nil, err
+		}
+		authority = authority[idx+1:]
+	}
+	for _, host := range strings.Split(authority, ",") {
+		if host == "" {
+			return nil, nil, fmt.Errorf("invalid dsn, empty broker host: %q", dsn)
+		}
+		if !hasPort(host) {
+			host += ":" + defaultBrokerPort
+		}
+		endpoints = append(endpoints, scheme+"://"+host)
+	}
+	u, err := url.Parse(pathQuery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+	if database := strings.Trim(u.Path, "/"); database != "" {
+		options.SetDatabase(database)
+	}
+	if err := applyParams(options, u.Query()); err != nil {
+		return nil, nil, err
+	}
+	return endpoints, options, nil
+}
+
+// parseUserInfo parses username/password for basic auth.
+func parseUserInfo(options *Options, userInfo string) error {
+	username, password, _ := strings.Cut(userInfo, ":")
+	username, err := url.PathUnescape(username)
+	if err != nil {
+		return fmt.Errorf("invalid dsn username: %w", err)
+	}
+	password, err = url.PathUnescape(password)
+	if err != nil {
+		return fmt.Errorf("invalid dsn password: %w", err)
+	}
+	options.SetCredentialsProvider(NewBasicAuth(username, password))
+	return nil
+}
+
+// hasPort returns if host contains port, supports IPv6 host like [::1]:9000.
+func hasPort(host string) bool {
+	return strings.LastIndex(host, ":") > strings.LastIndex(host, "]")
+}
+
+// applyParams applies DSN parameters to options in key order.
+func applyParams(options *Options, params url.Values) error {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range params[key] {
+			switch {
+			case strings.HasPrefix(key, tagParamPrefix) && len(key) > len(tagParamPrefix):
+				options.AddDefaultTag(strings.TrimPrefix(key, tagParamPrefix), value)
+			case strings.HasPrefix(key, headerParamPrefix) && len(key) > len(headerParamPrefix):
+				options.AddHeader(strings.TrimPrefix(key, headerParamPrefix), value)
+			default:
+				setter, ok := dsnParams[key]
+				if !ok {
+					return fmt.Errorf("unknown dsn parameter: %q", key)
+				}
+				if err := setter(options, value); err != nil {
+					return fmt.Errorf("invalid dsn parameter %q=%q: %w", key, value, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseTLSVersion parses TLS version like 1.2.
+func parseTLSVersion(value string) (uint16, error) {
+	switch value {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version, expect 1.0/1.1/1.2/1.3")
+	}
+}
+
+// stringParam returns the setter of string parameter.
+func stringParam(set func(o *Options, v string)) func(o *Options, value string) error {
+	return func(o *Options, value string) error {
+		set(o, value)
+		return nil
+	}
+}
+
+// intParam returns the setter of int parameter.
+func intParam(set func(o *Options, v int)) func(o *Options, value string) error {
+	return func(o *Options, value string) error {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		set(o, v)
+		return nil
+	}
+}
+
+// boolParam returns the setter of bool parameter.
+func boolParam(set func(o *Options, v bool)) func(o *Options, value string) error {
+	return func(o *Options, value string) error {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		set(o, v)
+		return nil
+	}
+}
+
+// durationParam returns the setter of duration parameter(e.g. 2s, 500ms), value is converted into given unit.
+func durationParam(unit time.Duration, set func(o *Options, v int64)) func(o *Options, value string) error {
+	return func(o *Options, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		if d%unit != 0 {
+			return fmt.Errorf("duration must be multiple of %s", unit)
+		}
+		set(o, int64(d/unit))
+		return nil
+	}
+}
@@ -58,6 +58,6 @@ func WriteData() {
 			AddField(api.NewLast("total", 24.0)))
 	}
 
-	// close write client
-	w.Close()
+	// close client, closes all write clients(flushes pending data) and releases connections
+	_ = cli.Close(context.TODO())
 }
@@ -0,0 +1,39 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lindb
+
+import (
+	"context"
+
+	"github.com/lindb/client_go/internal/http"
+)
+
+// HeaderHook represents the hook which sets custom headers(tenant id, request id etc.) for request.
+type HeaderHook = http.HeaderHook
+
+// WithHeaderHook returns a copy of ctx carrying the header hook,
+// hook is invoked for the query request sent with returned ctx.
+func WithHeaderHook(ctx context.Context, hook HeaderHook) context.Context {
+	return http.WithHeaderHook(ctx, hook)
+}
+
+// WithHeaders returns a copy of ctx carrying the headers,
+// headers are set for the query request sent with returned ctx.
+func WithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return http.WithHeaders(ctx, headers)
+}
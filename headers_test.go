@@ -0,0 +1,65 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lindb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/api"
+)
+
+func TestHeaders(t *testing.T) {
+	var headers []http.Header
+	var mutex sync.Mutex
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		headers = append(headers, r.Header.Clone())
+		mutex.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer svr.Close()
+
+	cli := NewClientWithOptions(svr.URL, DefaultOptions().
+		AddHeader("X-Tenant", "tenant-1").
+		SetWriteHeaderHook(func(_ context.Context, header http.Header) {
+			header.Set("X-Request-Id", "write")
+		}))
+	ctx := WithHeaderHook(WithHeaders(context.TODO(), map[string]string{"X-Route": "zone-a"}),
+		func(_ context.Context, header http.Header) {
+			header.Set("X-Request-Id", "query")
+		})
+	_, err := cli.DataQuery().DataQuery(ctx, "test", "select load from cpu")
+	assert.NoError(t, err)
+	w := cli.Write("test")
+	w.AddPoint(context.TODO(), api.NewPoint("cpu").AddField(api.NewSum("load", 1.0)))
+	assert.NoError(t, cli.Close(context.TODO()))
+
+	assert.Len(t, headers, 2)
+	assert.Equal(t, "tenant-1", headers[0].Get("X-Tenant"))
+	assert.Equal(t, "zone-a", headers[0].Get("X-Route"))
+	assert.Equal(t, "query", headers[0].Get("X-Request-Id"))
+	assert.Equal(t, "tenant-1", headers[1].Get("X-Tenant"))
+	assert.Empty(t, headers[1].Get("X-Route"))
+	assert.Equal(t, "write", headers[1].Get("X-Request-Id"))
+}
@@ -0,0 +1,219 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lindb/common/pkg/encoding"
+
+	httppkg "github.com/lindb/client_go/internal/http"
+)
+
+const (
+	// BrokerStatePath represents the cluster state api which returns live broker nodes.
+	BrokerStatePath = "/api/v1/state/broker/alive"
+	// DefaultRefreshInterval represents the default interval of refreshing live broker nodes.
+	DefaultRefreshInterval = 30 * time.Second
+)
+
+// Resolver represents the broker endpoint resolver.
+type Resolver interface {
+	// Endpoint returns a broker endpoint for sending request.
+	Endpoint() string
+	// Endpoints returns all known broker endpoints.
+	Endpoints() []string
+	// Close stops refreshing broker endpoints, known endpoints are still returned after closed.
+	Close()
+}
+
+// staticResolver implements Resolver interface, returns given endpoints by round-robin.
+type staticResolver struct {
+	endpoints []string
+	next      atomic.Uint64
+}
+
+// NewStaticResolver creates a Resolver with fixed broker endpoints(at least one).
+func NewStaticResolver(endpoint string, endpoints ...string) Resolver {
+	return &staticResolver{endpoints: append([]string{endpoint}, endpoints...)}
+}
+
+// Endpoint returns a fixed broker endpoint by round-robin.
+func (r *staticResolver) Endpoint() string {
+	if len(r.endpoints) == 1 {
+		return r.endpoints[0]
+	}
+	idx := r.next.Add(1)
+	return r.endpoints[idx%uint64(len(r.endpoints))]
+}
+
+// Endpoints returns the fixed broker endpoints.
+func (r *staticResolver) Endpoints() []string {
+	return r.endpoints
+}
+
+// Close does nothing, fixed broker endpoints are not refreshed.
+func (r *staticResolver) Close() {}
+
+// node represents the live broker node returned by cluster state api.
+type node struct {
+	HostIP   string `json:"hostIp"`
+	HostName string `json:"hostName"`
+	HTTPPort uint16 `json:"httpPort"`
+}
+
+// brokerResolver implements Resolver interface, discovers live broker nodes from cluster state api.
+type brokerResolver struct {
+	seeds    []string
+	scheme   string
+	interval time.Duration
+	client   *http.Client
+
+	endpoints atomic.Value // []string
+	next      atomic.Uint64
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+	mutex     sync.Mutex
+}
+
+// NewBrokerResolver creates a Resolver which fetches live broker nodes via seed endpoints(at least one),
+// broker nodes are fetched in background immediately, then refreshed periodically until closed,
+// seed endpoints are used before broker nodes fetched. Seed endpoint without scheme(e.g. host:9000) uses http.
+func NewBrokerResolver(client *http.Client, interval time.Duration, seed string, seeds ...string) Resolver {
+	seeds = append([]string{seed}, seeds...)
+	for i := range seeds {
+		seeds[i] = withScheme(seeds[i])
+	}
+	scheme := "http"
+	if u, err := url.Parse(seeds[0]); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &brokerResolver{
+		seeds:    seeds,
+		scheme:   scheme,
+		interval: interval,
+		client:   client,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	r.endpoints.Store(seeds)
+	go r.run()
+	return r
+}
+
+// Endpoint returns a live broker endpoint by round-robin.
+func (r *brokerResolver) Endpoint() string {
+	endpoints := r.Endpoints()
+	idx := r.next.Add(1)
+	return endpoints[idx%uint64(len(endpoints))]
+}
+
+// Endpoints returns all live broker endpoints.
+func (r *brokerResolver) Endpoints() []string {
+	return r.endpoints.Load().([]string)
+}
+
+// Close stops refreshing broker nodes, cancels in-flight fetching, then waits refreshing goroutine exited.
+func (r *brokerResolver) Close() {
+	r.cancel()
+	<-r.done
+}
+
+// run fetches broker nodes at first time, then refreshes broker nodes when interval elapsed until closed.
+func (r *brokerResolver) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		_ = r.refresh(r.ctx)
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh fetches live broker nodes from cluster state api,
+// keeps previous endpoints if fetch failure or no live node.
+func (r *brokerResolver) refresh(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// try current endpoints one by one, fallback to seed endpoints
+	current := r.Endpoints()
+	candidates := make([]string, 0, len(current)+len(r.seeds))
+	candidates = append(candidates, current...)
+	candidates = append(candidates, r.seeds...)
+	var lastErr error
+	for _, candidate := range candidates {
+		resp, err := httppkg.DoGet(ctx, r.client, candidate+BrokerStatePath)
+		if err != nil {
+			if ctx.Err() != nil {
+				// resolver closed
+				return ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+		var nodes []node
+		if err := encoding.JSONUnmarshal(resp, &nodes); err != nil {
+			lastErr = err
+			continue
+		}
+		endpoints := make([]string, 0, len(nodes))
+		for _, n := range nodes {
+			host := n.HostIP
+			if host == "" {
+				host = n.HostName
+			}
+			if host == "" || n.HTTPPort == 0 {
+				continue
+			}
+			endpoints = append(endpoints, fmt.Sprintf("%s://%s:%d", r.scheme, host, n.HTTPPort))
+		}
+		if len(endpoints) == 0 {
+			return fmt.Errorf("no live broker node found from %s", candidate)
+		}
+		r.endpoints.Store(endpoints)
+		return nil
+	}
+	return lastErr
+}
+
+// withScheme returns endpoint with scheme, http is used if endpoint has no scheme(e.g. host:9000).
+func withScheme(endpoint string) string {
+	if strings.Contains(endpoint, "://") {
+		return endpoint
+	}
+	return "http://" + endpoint
+}
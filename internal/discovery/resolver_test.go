@@ -0,0 +1,168 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	httppkg "github.com/lindb/client_go/internal/http"
+)
+
+// stateServer represents a stand-in broker which serves cluster state api.
+type stateServer struct {
+	*httptest.Server
+	nodes []string
+	mutex sync.Mutex
+}
+
+func newStateServer() *stateServer {
+	s := &stateServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != BrokerStatePath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		_, _ = w.Write([]byte("["))
+		for i, n := range s.nodes {
+			if i > 0 {
+				_, _ = w.Write([]byte(","))
+			}
+			_, _ = w.Write([]byte(n))
+		}
+		_, _ = w.Write([]byte("]"))
+	}))
+	return s
+}
+
+func (s *stateServer) setNodes(nodes ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.nodes = nodes
+}
+
+func TestStaticResolver(t *testing.T) {
+	r := NewStaticResolver("http://localhost:9000")
+	assert.Equal(t, "http://localhost:9000", r.Endpoint())
+	assert.Equal(t, []string{"http://localhost:9000"}, r.Endpoints())
+
+	r = NewStaticResolver("http://broker1:9000", "http://broker2:9000")
+	assert.NotEqual(t, r.Endpoint(), r.Endpoint())
+	assert.Len(t, r.Endpoints(), 2)
+	r.Close()
+}
+
+func TestBrokerResolver(t *testing.T) {
+	svr := newStateServer()
+	defer svr.Close()
+
+	addr := svr.Listener.Addr().(*net.TCPAddr)
+	self := fmt.Sprintf(`{"hostIp":"%s","httpPort":%d}`, addr.IP, addr.Port)
+
+	svr.setNodes(self, `{"hostName":"broker2","httpPort":9000}`)
+	r := NewBrokerResolver(httppkg.DefaultOptions().HTTPClient(), 10*time.Millisecond, svr.URL)
+	defer r.Close()
+	// broker nodes fetched in background
+	assert.Eventually(t, func() bool {
+		return len(r.Endpoints()) == 2
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{svr.URL, "http://broker2:9000"}, r.Endpoints())
+	// round-robin
+	assert.NotEqual(t, r.Endpoint(), r.Endpoint())
+
+	// broker tier scaled during run, refreshed periodically without requests
+	svr.setNodes(self, `{"hostIp":"10.0.0.1","httpPort":9000}`, `{"hostIp":"10.0.0.2","httpPort":9000}`)
+	assert.Eventually(t, func() bool {
+		return len(r.Endpoints()) == 3
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{svr.URL, "http://10.0.0.1:9000", "http://10.0.0.2:9000"}, r.Endpoints())
+
+	// no live node, keep previous endpoints
+	svr.setNodes(`{"hostIp":"","httpPort":9000}`)
+	assert.Error(t, r.(*brokerResolver).refresh(context.TODO()))
+	assert.Len(t, r.Endpoints(), 3)
+}
+
+func TestBrokerResolver_Close(t *testing.T) {
+	svr := newStateServer()
+	defer svr.Close()
+
+	addr := svr.Listener.Addr().(*net.TCPAddr)
+	svr.setNodes(fmt.Sprintf(`{"hostIp":"%s","httpPort":%d}`, addr.IP, addr.Port))
+	r := NewBrokerResolver(httppkg.DefaultOptions().HTTPClient(), 10*time.Millisecond, svr.URL)
+	r.Close()
+	r.Close()
+	// not refreshed after closed
+	svr.setNodes(`{"hostIp":"10.0.0.1","httpPort":9000}`, `{"hostIp":"10.0.0.2","httpPort":9000}`)
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, r.Endpoints(), 1)
+	assert.NotEmpty(t, r.Endpoint())
+}
+
+func TestBrokerResolver_Seeds(t *testing.T) {
+	// fetching broker nodes not blocking
+	block := make(chan struct{})
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer svr.Close()
+	defer close(block)
+
+	host := strings.TrimPrefix(svr.URL, "http://")
+	r := NewBrokerResolver(&http.Client{}, 0, host, "https://broker2:9000")
+	assert.Equal(t, []string{svr.URL, "https://broker2:9000"}, r.Endpoints())
+	assert.Equal(t, "http", r.(*brokerResolver).scheme)
+	assert.Equal(t, DefaultRefreshInterval, r.(*brokerResolver).interval)
+	// in-flight fetching canceled by close
+	r.Close()
+}
+
+func TestBrokerResolver_Failure(t *testing.T) {
+	r := NewBrokerResolver(&http.Client{}, time.Minute, "https://127.0.0.1:0")
+	defer r.Close()
+	assert.Equal(t, "https://127.0.0.1:0", r.Endpoint())
+	assert.Equal(t, "https", r.(*brokerResolver).scheme)
+	assert.Error(t, r.(*brokerResolver).refresh(context.TODO()))
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("abc"))
+	}))
+	defer svr.Close()
+	r2 := NewBrokerResolver(&http.Client{}, time.Minute, svr.URL)
+	defer r2.Close()
+	assert.Error(t, r2.(*brokerResolver).refresh(context.TODO()))
+	assert.Equal(t, []string{svr.URL}, r2.Endpoints())
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	assert.ErrorIs(t, r2.(*brokerResolver).refresh(ctx), context.Canceled)
+}
@@ -0,0 +1,201 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// for testing
+var (
+	nowFn = time.Now
+)
+
+// ErrCircuitOpen represents the error returned without sending request if circuit breaker of endpoint is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState represents the state of circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed represents requests are sent normally.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen represents requests fail fast until cool-down elapsed.
+	CircuitOpen
+	// CircuitHalfOpen represents a single probe request is sent, other requests fail fast.
+	CircuitHalfOpen
+)
+
+// String returns the string value of circuit state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// CircuitStateHook represents the hook invoked when circuit state of endpoint(host:port) changed.
+type CircuitStateHook func(endpoint string, from, to CircuitState)
+
+// circuitBreaker represents the circuit breaker of an endpoint.
+type circuitBreaker struct {
+	endpoint         string
+	failureThreshold int
+	coolDown         time.Duration
+
+	state    CircuitState
+	failures int       // consecutive failures
+	openedAt time.Time // time of circuit opened
+	probing  bool      // whether probe request is in flight
+
+	mutex sync.Mutex
+}
+
+// allow returns if request can be sent, transits circuit from open to half-open if cool-down elapsed.
+func (b *circuitBreaker) allow() (ok bool, from, to CircuitState) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	from = b.state
+	switch b.state {
+	case CircuitOpen:
+		if nowFn().Sub(b.openedAt) < b.coolDown {
+			return false, from, b.state
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true, from, b.state
+	case CircuitHalfOpen:
+		if b.probing {
+			return false, from, b.state
+		}
+		b.probing = true
+		return true, from, b.state
+	default:
+		return true, from, b.state
+	}
+}
+
+// done records the result of request, opens circuit if failures reach threshold or probe request failed.
+func (b *circuitBreaker) done(success bool) (from, to CircuitState) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	from = b.state
+	if b.state == CircuitHalfOpen {
+		b.probing = false
+	}
+	if success {
+		b.failures = 0
+		b.state = CircuitClosed
+		return from, b.state
+	}
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.failureThreshold {
+		b.state = CircuitOpen
+		b.openedAt = nowFn()
+	}
+	return from, b.state
+}
+
+// cancel releases probe request without changing circuit state if request canceled by caller.
+func (b *circuitBreaker) cancel() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.probing = false
+}
+
+// circuitBreakerRoundTripper represents the round tripper which fails fast if circuit breaker of endpoint is open,
+// circuit breakers are shared by all write/query requests sent by the same HTTP client.
+type circuitBreakerRoundTripper struct {
+	next             http.RoundTripper
+	failureThreshold int
+	coolDown         time.Duration
+	hook             CircuitStateHook
+
+	breakers map[string]*circuitBreaker
+	mutex    sync.Mutex
+}
+
+// RoundTrip executes a single HTTP transaction if circuit breaker of endpoint allows,
+// transport error, timeout or server error(5xx) is treated as failure.
+func (rt *circuitBreakerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	breaker := rt.getBreaker(req.URL.Host)
+	ok, from, to := breaker.allow()
+	rt.notify(breaker.endpoint, from, to)
+	if !ok {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, breaker.endpoint)
+	}
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil && errors.Is(req.Context().Err(), context.Canceled) {
+		// request canceled by caller, not failure of endpoint,
+		// timeout(deadline exceeded of request context or http client) is treated as failure
+		breaker.cancel()
+		return resp, err
+	}
+	from, to = breaker.done(err == nil && resp.StatusCode < http.StatusInternalServerError)
+	rt.notify(breaker.endpoint, from, to)
+	return resp, err
+}
+
+// CloseIdleConnections closes idle connections of next round tripper if supported.
+func (rt *circuitBreakerRoundTripper) CloseIdleConnections() {
+	closeIdleConnections(rt.next)
+}
+
+// getBreaker returns the circuit breaker of endpoint, creates it if not exist.
+func (rt *circuitBreakerRoundTripper) getBreaker(endpoint string) *circuitBreaker {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	if rt.breakers == nil {
+		rt.breakers = make(map[string]*circuitBreaker)
+	}
+	breaker, ok := rt.breakers[endpoint]
+	if !ok {
+		breaker = &circuitBreaker{
+			endpoint:         endpoint,
+			failureThreshold: rt.failureThreshold,
+			coolDown:         rt.coolDown,
+		}
+		rt.breakers[endpoint] = breaker
+	}
+	return breaker
+}
+
+// notify invokes state hook if circuit state changed.
+func (rt *circuitBreakerRoundTripper) notify(endpoint string, from, to CircuitState) {
+	if from != to && rt.hook != nil {
+		rt.hook(endpoint, from, to)
+	}
+}
@@ -0,0 +1,192 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitState_String(t *testing.T) {
+	assert.Equal(t, "closed", CircuitClosed.String())
+	assert.Equal(t, "open", CircuitOpen.String())
+	assert.Equal(t, "half-open", CircuitHalfOpen.String())
+	assert.Equal(t, "unknown(10)", CircuitState(10).String())
+}
+
+func TestCircuitBreakerRoundTripper(t *testing.T) {
+	now := time.Now()
+	var nowMutex sync.Mutex
+	nowFn = func() time.Time {
+		nowMutex.Lock()
+		defer nowMutex.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		nowMutex.Lock()
+		defer nowMutex.Unlock()
+		now = now.Add(d)
+	}
+	defer func() {
+		nowFn = time.Now
+	}()
+
+	var (
+		status   atomic.Int32
+		requests atomic.Int32
+	)
+	status.Store(http.StatusInternalServerError)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer svr.Close()
+
+	var (
+		transitions []string
+		mutex       sync.Mutex
+	)
+	opt := DefaultOptions().SetCircuitBreaker(2, 10).
+		SetCircuitStateHook(func(endpoint string, from, to CircuitState) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			assert.Equal(t, strings.TrimPrefix(svr.URL, "http://"), endpoint)
+			transitions = append(transitions, from.String()+"->"+to.String())
+		})
+	failureThreshold, coolDown := opt.CircuitBreaker()
+	assert.Equal(t, 2, failureThreshold)
+	assert.Equal(t, int64(10), coolDown)
+	assert.NotNil(t, opt.CircuitStateHook())
+	cli := opt.HTTPClient()
+
+	// open after consecutive failures
+	for i := 0; i < 2; i++ {
+		_, err := DoGet(context.TODO(), cli, svr.URL)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+	_, err := DoPut(context.TODO(), cli, svr.URL, []byte("data"))
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(2), requests.Load())
+
+	// probe failed, open again
+	advance(10 * time.Second)
+	_, err = DoGet(context.TODO(), cli, svr.URL)
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+	_, err = DoGet(context.TODO(), cli, svr.URL)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(3), requests.Load())
+
+	// probe succeeded, closed
+	advance(10 * time.Second)
+	status.Store(http.StatusOK)
+	_, err = DoGet(context.TODO(), cli, svr.URL)
+	assert.NoError(t, err)
+	_, err = DoGet(context.TODO(), cli, svr.URL)
+	assert.NoError(t, err)
+
+	// client error is not failure of endpoint
+	status.Store(http.StatusBadRequest)
+	for i := 0; i < 3; i++ {
+		_, err = DoGet(context.TODO(), cli, svr.URL)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+
+	assert.Equal(t, []string{
+		"closed->open",
+		"open->half-open", "half-open->open",
+		"open->half-open", "half-open->closed",
+	}, transitions)
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	now := time.Now()
+	nowFn = func() time.Time { return now }
+	defer func() {
+		nowFn = time.Now
+	}()
+
+	b := &circuitBreaker{endpoint: "localhost:9000", failureThreshold: 1, coolDown: time.Second}
+	ok, _, _ := b.allow()
+	assert.True(t, ok)
+	from, to := b.done(false)
+	assert.Equal(t, CircuitClosed, from)
+	assert.Equal(t, CircuitOpen, to)
+
+	now = now.Add(time.Second)
+	ok, from, to = b.allow()
+	assert.True(t, ok)
+	assert.Equal(t, CircuitOpen, from)
+	assert.Equal(t, CircuitHalfOpen, to)
+	// single probe request
+	ok, _, _ = b.allow()
+	assert.False(t, ok)
+	// probe canceled, allow next probe
+	b.cancel()
+	ok, _, to = b.allow()
+	assert.True(t, ok)
+	assert.Equal(t, CircuitHalfOpen, to)
+}
+
+func TestCircuitBreakerRoundTripper_Canceled(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer svr.Close()
+
+	cli := DefaultOptions().SetCircuitBreaker(1, 10).HTTPClient()
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithCancel(context.TODO())
+		timer := time.AfterFunc(10*time.Millisecond, cancel)
+		_, err := DoGet(ctx, cli, svr.URL)
+		timer.Stop()
+		cancel()
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+}
+
+func TestCircuitBreakerRoundTripper_Timeout(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer svr.Close()
+
+	var states []CircuitState
+	cli := DefaultOptions().SetReqTimeout(1).SetCircuitBreaker(1, 30).
+		SetCircuitStateHook(func(_ string, _, to CircuitState) {
+			states = append(states, to)
+		}).HTTPClient()
+	// request timeout of http client is failure of endpoint
+	_, err := DoGet(context.TODO(), cli, svr.URL)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, []CircuitState{CircuitOpen}, states)
+
+	_, err = DoGet(context.TODO(), cli, svr.URL)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+}
@@ -35,7 +35,17 @@ var (
 
 // DoPut sends put request based on given client/endpoint/request body.
 func DoPut(ctx context.Context, cli *http.Client, endpoint string, body []byte) ([]byte, error) {
-	req, err := newRequestFn(ctx, http.MethodPut, endpoint, bytes.NewBuffer(body))
+	return doRequest(ctx, cli, http.MethodPut, endpoint, bytes.NewBuffer(body))
+}
+
+// DoGet sends get request based on given client/endpoint.
+func DoGet(ctx context.Context, cli *http.Client, endpoint string) ([]byte, error) {
+	return doRequest(ctx, cli, http.MethodGet, endpoint, http.NoBody)
+}
+
+// doRequest sends request based on given client/method/endpoint/request body, returns response body.
+func doRequest(ctx context.Context, cli *http.Client, method, endpoint string, body io.Reader) ([]byte, error) {
+	req, err := newRequestFn(ctx, method, endpoint, body)
 	if err != nil {
 		return nil, err
 	}
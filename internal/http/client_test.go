@@ -177,3 +177,18 @@ func TestClient_DoPut(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_DoGet(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte("Good!"))
+	}))
+	defer ts.Close()
+
+	resp, err := DoGet(context.TODO(), &http.Client{}, ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("Good!"), resp)
+}
@@ -0,0 +1,125 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sync"
+)
+
+var (
+	errEmptyToken = errors.New("empty token returned by token fetcher")
+)
+
+// CredentialsProvider represents the credentials provider for authenticating request.
+type CredentialsProvider interface {
+	// Authorization returns the value of Authorization header for request.
+	Authorization(ctx context.Context) (string, error)
+	// Invalidate invalidates current credentials after request unauthorized(401),
+	// returns true if new credentials can be provided, then request will be retried.
+	Invalidate() bool
+}
+
+// basicAuth implements CredentialsProvider interface, provides static basic auth.
+type basicAuth struct {
+	authorization string
+}
+
+// NewBasicAuth creates a CredentialsProvider with static username/password.
+func NewBasicAuth(username, password string) CredentialsProvider {
+	return &basicAuth{
+		authorization: "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)),
+	}
+}
+
+// Authorization returns the basic auth header value.
+func (b *basicAuth) Authorization(_ context.Context) (string, error) {
+	return b.authorization, nil
+}
+
+// Invalidate returns false, static credentials cannot be refreshed.
+func (b *basicAuth) Invalidate() bool {
+	return false
+}
+
+// bearerToken implements CredentialsProvider interface, provides static bearer token.
+type bearerToken struct {
+	authorization string
+}
+
+// NewBearerToken creates a CredentialsProvider with static bearer token.
+func NewBearerToken(token string) CredentialsProvider {
+	return &bearerToken{
+		authorization: "Bearer " + token,
+	}
+}
+
+// Authorization returns the bearer token header value.
+func (b *bearerToken) Authorization(_ context.Context) (string, error) {
+	return b.authorization, nil
+}
+
+// Invalidate returns false, static credentials cannot be refreshed.
+func (b *bearerToken) Invalidate() bool {
+	return false
+}
+
+// refreshingToken implements CredentialsProvider interface, provides bearer token fetched by fetcher,
+// caches token until request unauthorized.
+type refreshingToken struct {
+	fetcher func(ctx context.Context) (string, error)
+	token   string
+	mutex   sync.Mutex
+}
+
+// NewRefreshingToken creates a CredentialsProvider with bearer token fetched by fetcher,
+// token is re-fetched after request unauthorized(401).
+func NewRefreshingToken(fetcher func(ctx context.Context) (string, error)) CredentialsProvider {
+	return &refreshingToken{
+		fetcher: fetcher,
+	}
+}
+
+// Authorization returns the bearer token header value, fetches token if not cached.
+func (r *refreshingToken) Authorization(ctx context.Context) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.token == "" {
+		token, err := r.fetcher(ctx)
+		if err != nil {
+			return "", err
+		}
+		if token == "" {
+			return "", errEmptyToken
+		}
+		r.token = token
+	}
+	return "Bearer " + r.token, nil
+}
+
+// Invalidate discards cached token, then token will be re-fetched.
+func (r *refreshingToken) Invalidate() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.token = ""
+	return true
+}
@@ -0,0 +1,68 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentials_Static(t *testing.T) {
+	auth, err := NewBasicAuth("admin", "admin123").Authorization(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "Basic YWRtaW46YWRtaW4xMjM=", auth)
+	assert.False(t, NewBasicAuth("admin", "admin123").Invalidate())
+
+	auth, err = NewBearerToken("token").Authorization(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token", auth)
+	assert.False(t, NewBearerToken("token").Invalidate())
+}
+
+func TestCredentials_RefreshingToken(t *testing.T) {
+	count := 0
+	provider := NewRefreshingToken(func(_ context.Context) (string, error) {
+		count++
+		return fmt.Sprintf("token-%d", count), nil
+	})
+	auth, err := provider.Authorization(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token-1", auth)
+	// cached
+	auth, _ = provider.Authorization(context.TODO())
+	assert.Equal(t, "Bearer token-1", auth)
+
+	assert.True(t, provider.Invalidate())
+	auth, _ = provider.Authorization(context.TODO())
+	assert.Equal(t, "Bearer token-2", auth)
+
+	provider = NewRefreshingToken(func(_ context.Context) (string, error) {
+		return "", fmt.Errorf("err")
+	})
+	_, err = provider.Authorization(context.TODO())
+	assert.Error(t, err)
+
+	provider = NewRefreshingToken(func(_ context.Context) (string, error) {
+		return "", nil
+	})
+	_, err = provider.Authorization(context.TODO())
+	assert.Equal(t, errEmptyToken, err)
+}
@@ -0,0 +1,88 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+)
+
+// headerHooksKey represents the context key of header hooks.
+type headerHooksKey struct{}
+
+// HeaderHook represents the hook which sets custom headers(tenant id, request id etc.) for request.
+type HeaderHook func(ctx context.Context, header http.Header)
+
+// WithHeaderHook returns a copy of ctx carrying the header hook,
+// hook is invoked for the request sent with returned ctx.
+func WithHeaderHook(ctx context.Context, hook HeaderHook) context.Context {
+	if hook == nil {
+		return ctx
+	}
+	hooks := headerHooks(ctx)
+	newHooks := make([]HeaderHook, 0, len(hooks)+1)
+	newHooks = append(newHooks, hooks...)
+	newHooks = append(newHooks, hook)
+	return context.WithValue(ctx, headerHooksKey{}, newHooks)
+}
+
+// WithHeaders returns a copy of ctx carrying the headers,
+// headers are set for the request sent with returned ctx.
+func WithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	return WithHeaderHook(ctx, func(_ context.Context, header http.Header) {
+		for k, v := range headers {
+			header.Set(k, v)
+		}
+	})
+}
+
+// headerHooks returns the header hooks carried by ctx.
+func headerHooks(ctx context.Context) []HeaderHook {
+	hooks, _ := ctx.Value(headerHooksKey{}).([]HeaderHook)
+	return hooks
+}
+
+// headerRoundTripper represents the round tripper which sets static headers and headers from hooks for each request.
+type headerRoundTripper struct {
+	next    http.RoundTripper
+	headers map[string]string
+}
+
+// RoundTrip executes a single HTTP transaction with custom headers.
+func (rt *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	hooks := headerHooks(req.Context())
+	if len(rt.headers) == 0 && len(hooks) == 0 {
+		return rt.next.RoundTrip(req)
+	}
+	r := req.Clone(req.Context())
+	for k, v := range rt.headers {
+		r.Header.Set(k, v)
+	}
+	for _, hook := range hooks {
+		hook(req.Context(), r.Header)
+	}
+	return rt.next.RoundTrip(r)
+}
+
+// CloseIdleConnections closes idle connections of next round tripper if supported.
+func (rt *headerRoundTripper) CloseIdleConnections() {
+	closeIdleConnections(rt.next)
+}
@@ -0,0 +1,54 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaders(t *testing.T) {
+	ctx := context.TODO()
+	assert.Equal(t, ctx, WithHeaderHook(ctx, nil))
+	assert.Equal(t, ctx, WithHeaders(ctx, nil))
+
+	ctx = WithHeaders(ctx, map[string]string{"X-Tenant": "t1", "X-Request-Id": "r1"})
+	ctx = WithHeaderHook(ctx, func(_ context.Context, header http.Header) {
+		header.Set("X-Request-Id", "r2")
+	})
+	assert.Len(t, headerHooks(ctx), 2)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Tenant") + "," + r.Header.Get("X-Request-Id") + "," + r.Header.Get("X-Route")))
+	}))
+	defer svr.Close()
+
+	opt := DefaultOptions().AddHeader("X-Route", "zone-a").AddHeader("X-Tenant", "t0")
+	assert.Equal(t, map[string]string{"X-Route": "zone-a", "X-Tenant": "t0"}, opt.Headers())
+	resp, err := DoPut(ctx, opt.HTTPClient(), svr.URL, []byte("data"))
+	assert.NoError(t, err)
+	assert.Equal(t, "t1,r2,zone-a", string(resp))
+
+	resp, err = DoGet(context.TODO(), DefaultOptions().HTTPClient(), svr.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, ",,", string(resp))
+}
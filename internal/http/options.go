@@ -22,8 +22,12 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"runtime"
+	"sync"
 	"time"
+
+	"github.com/lindb/client_go/trace"
 )
 
 var (
@@ -36,28 +40,149 @@ type Options struct {
 	reqTimeout int64
 	// TLS configuration for secure connection, default nil.
 	tlsConfig *tls.Config
+	// CA bundle PEM file for verifying server certificate, reloaded when modified(checked every 10s), default empty.
+	caFile string
+	// Client certificate/key PEM files for mutual TLS, reloaded when modified(checked every 10s), default empty.
+	certFile, keyFile string
+	// Server name for verifying server certificate, default empty(host of endpoint).
+	serverName string
+	// Minimum TLS version, default 0(TLS 1.2 by crypto/tls).
+	minTLSVersion uint16
+	// Dial timeout(s) of establishing connection, default 5.
+	dialTimeout int64
+	// Keep-alive period(s) of active connection, default 30.
+	keepAlive int64
+	// TLS handshake timeout(s), default 5.
+	tlsHandshakeTimeout int64
+	// Maximum number of idle connections across all hosts, default 100.
+	maxIdleConns int
+	// Maximum number of idle connections per host, default 100.
+	maxIdleConnsPerHost int
+	// Maximum number of connections per host, default 0(no limit).
+	maxConnsPerHost int
+	// Idle connection timeout(s), default 90.
+	idleConnTimeout int64
+	// Whether to attempt HTTP/2 for connection, default false.
+	enableHTTP2 bool
+	// Proxy function for request, default nil(no proxy).
+	proxy func(*http.Request) (*url.URL, error)
+	// Custom round tripper used as transport of HTTP client, default nil.
+	roundTripper http.RoundTripper
+	// Custom HTTP client used for all requests, default nil.
+	httpClient *http.Client
+	// Credentials provider for authenticating request, default nil.
+	credentials CredentialsProvider
+	// Static headers set for each request, default nil.
+	headers map[string]string
+	// Tracer for creating spans around requests, default nil(no tracing).
+	tracer trace.Tracer
+	// Consecutive failures of endpoint to open circuit breaker, default 0(circuit breaker disabled).
+	circuitFailureThreshold int
+	// Cool-down(s) of open circuit breaker before probing endpoint, default 30.
+	circuitCoolDown int64
+	// Hook invoked when circuit state of endpoint changed, default nil.
+	circuitStateHook CircuitStateHook
+
+	// HTTP client shared by all requests, built lazily and rebuilt after setting changed.
+	client *http.Client
+	mutex  sync.Mutex
 }
 
-// HTTPClient returns the HTTP client with setting.
+// HTTPClient returns the shared HTTP client with setting, all requests reuse the same transport(connection pool).
 func (o *Options) HTTPClient() *http.Client {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.client == nil {
+		o.client = o.newHTTPClient()
+	}
+	return o.client
+}
+
+// newHTTPClient creates a new HTTP client with setting.
+// If custom HTTP client set, uses a copy of it which transport wrapped.
+func (o *Options) newHTTPClient() *http.Client {
+	if o.httpClient != nil {
+		cli := *o.httpClient
+		transport := cli.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		cli.Transport = o.wrapTransport(transport)
+		return &cli
+	}
+	transport := o.roundTripper
+	if transport == nil {
+		transport = o.newTransport()
+	}
+	reqTimeout := o.reqTimeout
+	if reqTimeout <= 0 {
+		// invalid request timeout, use default
+		reqTimeout = DefaultOptions().reqTimeout
+	}
 	return &http.Client{
-		Timeout: time.Second * time.Duration(o.reqTimeout),
-		Transport: &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout: 5 * time.Second,
-			}).DialContext,
-			TLSHandshakeTimeout: 5 * time.Second,
-			TLSClientConfig:     o.TLSConfig(),
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 100,
-			IdleConnTimeout:     90 * time.Second,
-		},
+		Timeout:   time.Second * time.Duration(reqTimeout),
+		Transport: o.wrapTransport(transport),
+	}
+}
+
+// wrapTransport wraps transport for applying setting(credentials/headers/circuit breaker/tracing etc.) to each request.
+func (o *Options) wrapTransport(transport http.RoundTripper) http.RoundTripper {
+	if o.credentials != nil {
+		transport = &authRoundTripper{next: transport, credentials: o.credentials}
+	}
+	headers := make(map[string]string, len(o.headers))
+	for k, v := range o.headers {
+		headers[k] = v
+	}
+	transport = &headerRoundTripper{next: transport, headers: headers}
+	if o.circuitFailureThreshold > 0 {
+		transport = &circuitBreakerRoundTripper{
+			next:             transport,
+			failureThreshold: o.circuitFailureThreshold,
+			coolDown:         time.Duration(o.circuitCoolDown) * time.Second,
+			hook:             o.circuitStateHook,
+		}
+	}
+	if o.tracer != nil {
+		transport = &tracingRoundTripper{next: transport, tracer: o.tracer}
+	}
+	return transport
+}
+
+// newTransport creates a new HTTP transport with setting,
+// if CA bundle file set, transport is rebuilt when CA bundle file modified.
+func (o *Options) newTransport() http.RoundTripper {
+	certs := newCertificates(o.caFile, o.certFile, o.keyFile)
+	transport := o.newHTTPTransport(o.newTLSConfig(certs))
+	if o.caFile == "" {
+		return transport
+	}
+	return newCAReloadTransport(certs, transport)
+}
+
+// newHTTPTransport creates a new HTTP transport with setting and TLS configuration.
+func (o *Options) newHTTPTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy: o.proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   time.Second * time.Duration(o.dialTimeout),
+			KeepAlive: time.Second * time.Duration(o.keepAlive),
+		}).DialContext,
+		ForceAttemptHTTP2:   o.enableHTTP2,
+		TLSHandshakeTimeout: time.Second * time.Duration(o.tlsHandshakeTimeout),
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConns:        o.maxIdleConns,
+		MaxIdleConnsPerHost: o.maxIdleConnsPerHost,
+		MaxConnsPerHost:     o.maxConnsPerHost,
+		IdleConnTimeout:     time.Second * time.Duration(o.idleConnTimeout),
 	}
 }
 
 // SetReqTimeout sets the request timeout.
 func (o *Options) SetReqTimeout(timeout int64) *Options {
 	o.reqTimeout = timeout
+	o.reset()
 	return o
 }
 
@@ -69,6 +194,7 @@ func (o *Options) ReqTimeout() int64 {
 // SetTLSConfig sets TLS configuration for secure connection.
 func (o *Options) SetTLSConfig(tlsConfig *tls.Config) *Options {
 	o.tlsConfig = tlsConfig
+	o.reset()
 	return o
 }
 
@@ -77,9 +203,370 @@ func (o *Options) TLSConfig() *tls.Config {
 	return o.tlsConfig
 }
 
+// SetCAFile sets CA bundle PEM file for verifying server certificate, file is reloaded when modified(checked every 10s).
+func (o *Options) SetCAFile(caFile string) *Options {
+	o.caFile = caFile
+	o.reset()
+	return o
+}
+
+// CAFile returns CA bundle PEM file.
+func (o *Options) CAFile() string {
+	return o.caFile
+}
+
+// SetClientCertFile sets client certificate/key PEM files for mutual TLS, files are reloaded when modified(checked every 10s).
+func (o *Options) SetClientCertFile(certFile, keyFile string) *Options {
+	o.certFile = certFile
+	o.keyFile = keyFile
+	o.reset()
+	return o
+}
+
+// ClientCertFile returns client certificate/key PEM files.
+func (o *Options) ClientCertFile() (certFile, keyFile string) {
+	return o.certFile, o.keyFile
+}
+
+// SetServerName sets server name for verifying server certificate.
+func (o *Options) SetServerName(serverName string) *Options {
+	o.serverName = serverName
+	o.reset()
+	return o
+}
+
+// ServerName returns server name for verifying server certificate.
+func (o *Options) ServerName() string {
+	return o.serverName
+}
+
+// SetMinTLSVersion sets minimum TLS version, e.g. tls.VersionTLS12.
+func (o *Options) SetMinTLSVersion(version uint16) *Options {
+	o.minTLSVersion = version
+	o.reset()
+	return o
+}
+
+// MinTLSVersion returns minimum TLS version.
+func (o *Options) MinTLSVersion() uint16 {
+	return o.minTLSVersion
+}
+
+// newTLSConfig creates TLS configuration based on TLS config and certificate files setting,
+// CA pool(RootCAs) is set by transport which reloads CA bundle file.
+func (o *Options) newTLSConfig(certs *certificates) *tls.Config {
+	if o.caFile == "" && o.certFile == "" && o.serverName == "" && o.minTLSVersion == 0 {
+		return o.tlsConfig
+	}
+	cfg := &tls.Config{}
+	if o.tlsConfig != nil {
+		cfg = o.tlsConfig.Clone()
+	}
+	if o.serverName != "" {
+		cfg.ServerName = o.serverName
+	}
+	if o.minTLSVersion != 0 {
+		cfg.MinVersion = o.minTLSVersion
+	}
+	if o.certFile != "" {
+		cfg.GetClientCertificate = certs.clientCertificate
+	}
+	return cfg
+}
+
+// SetDialTimeout sets the dial timeout(s) of establishing connection.
+func (o *Options) SetDialTimeout(timeout int64) *Options {
+	o.dialTimeout = timeout
+	o.reset()
+	return o
+}
+
+// DialTimeout returns the dial timeout(s) of establishing connection.
+func (o *Options) DialTimeout() int64 {
+	return o.dialTimeout
+}
+
+// SetKeepAlive sets the keep-alive period(s) of active connection.
+func (o *Options) SetKeepAlive(keepAlive int64) *Options {
+	o.keepAlive = keepAlive
+	o.reset()
+	return o
+}
+
+// KeepAlive returns the keep-alive period(s) of active connection.
+func (o *Options) KeepAlive() int64 {
+	return o.keepAlive
+}
+
+// SetTLSHandshakeTimeout sets the TLS handshake timeout(s).
+func (o *Options) SetTLSHandshakeTimeout(timeout int64) *Options {
+	o.tlsHandshakeTimeout = timeout
+	o.reset()
+	return o
+}
+
+// TLSHandshakeTimeout returns the TLS handshake timeout(s).
+func (o *Options) TLSHandshakeTimeout() int64 {
+	return o.tlsHandshakeTimeout
+}
+
+// SetMaxIdleConns sets maximum number of idle connections across all hosts.
+func (o *Options) SetMaxIdleConns(maxIdleConns int) *Options {
+	o.maxIdleConns = maxIdleConns
+	o.reset()
+	return o
+}
+
+// MaxIdleConns returns maximum number of idle connections across all hosts.
+func (o *Options) MaxIdleConns() int {
+	return o.maxIdleConns
+}
+
+// SetMaxIdleConnsPerHost sets maximum number of idle connections per host.
+func (o *Options) SetMaxIdleConnsPerHost(maxIdleConnsPerHost int) *Options {
+	o.maxIdleConnsPerHost = maxIdleConnsPerHost
+	o.reset()
+	return o
+}
+
+// MaxIdleConnsPerHost returns maximum number of idle connections per host.
+func (o *Options) MaxIdleConnsPerHost() int {
+	return o.maxIdleConnsPerHost
+}
+
+// SetMaxConnsPerHost sets maximum number of connections per host, 0 means no limit.
+func (o *Options) SetMaxConnsPerHost(maxConnsPerHost int) *Options {
+	o.maxConnsPerHost = maxConnsPerHost
+	o.reset()
+	return o
+}
+
+// MaxConnsPerHost returns maximum number of connections per host.
+func (o *Options) MaxConnsPerHost() int {
+	return o.maxConnsPerHost
+}
+
+// SetIdleConnTimeout sets the idle connection timeout(s).
+func (o *Options) SetIdleConnTimeout(timeout int64) *Options {
+	o.idleConnTimeout = timeout
+	o.reset()
+	return o
+}
+
+// IdleConnTimeout returns the idle connection timeout(s).
+func (o *Options) IdleConnTimeout() int64 {
+	return o.idleConnTimeout
+}
+
+// SetEnableHTTP2 sets whether to attempt HTTP/2 for connection.
+func (o *Options) SetEnableHTTP2(enableHTTP2 bool) *Options {
+	o.enableHTTP2 = enableHTTP2
+	o.reset()
+	return o
+}
+
+// EnableHTTP2 returns whether to attempt HTTP/2 for connection.
+func (o *Options) EnableHTTP2() bool {
+	return o.enableHTTP2
+}
+
+// SetProxy sets proxy function for request, e.g. http.ProxyFromEnvironment or http.ProxyURL(url).
+func (o *Options) SetProxy(proxy func(*http.Request) (*url.URL, error)) *Options {
+	o.proxy = proxy
+	o.reset()
+	return o
+}
+
+// Proxy returns proxy function for request.
+func (o *Options) Proxy() func(*http.Request) (*url.URL, error) {
+	return o.proxy
+}
+
+// SetRoundTripper sets custom round tripper used as transport of HTTP client,
+// transport settings(dial/idle/TLS/proxy etc.) are ignored if set.
+func (o *Options) SetRoundTripper(roundTripper http.RoundTripper) *Options {
+	o.roundTripper = roundTripper
+	o.reset()
+	return o
+}
+
+// RoundTripper returns custom round tripper.
+func (o *Options) RoundTripper() http.RoundTripper {
+	return o.roundTripper
+}
+
+// SetHTTPClient sets custom HTTP client used for all requests, all other settings are ignored if set.
+func (o *Options) SetHTTPClient(httpClient *http.Client) *Options {
+	o.httpClient = httpClient
+	o.reset()
+	return o
+}
+
+// SetCredentialsProvider sets credentials provider for authenticating request.
+func (o *Options) SetCredentialsProvider(credentials CredentialsProvider) *Options {
+	o.credentials = credentials
+	o.reset()
+	return o
+}
+
+// CredentialsProvider returns credentials provider for authenticating request.
+func (o *Options) CredentialsProvider() CredentialsProvider {
+	return o.credentials
+}
+
+// AddHeader adds static header set for each request.
+func (o *Options) AddHeader(key, value string) *Options {
+	if o.headers == nil {
+		o.headers = make(map[string]string)
+	}
+	o.headers[key] = value
+	o.reset()
+	return o
+}
+
+// Headers returns static headers set for each request.
+func (o *Options) Headers() map[string]string {
+	return o.headers
+}
+
+// SetTracer sets tracer for creating spans around requests.
+func (o *Options) SetTracer(tracer trace.Tracer) *Options {
+	o.tracer = tracer
+	o.reset()
+	return o
+}
+
+// Tracer returns tracer for creating spans around requests.
+func (o *Options) Tracer() trace.Tracer {
+	return o.tracer
+}
+
+// SetCircuitBreaker sets consecutive failures of endpoint to open circuit breaker(0 means disabled),
+// and cool-down(s) of open circuit breaker before probing endpoint.
+func (o *Options) SetCircuitBreaker(failureThreshold int, coolDown int64) *Options {
+	o.circuitFailureThreshold = failureThreshold
+	o.circuitCoolDown = coolDown
+	o.reset()
+	return o
+}
+
+// CircuitBreaker returns consecutive failures of endpoint to open circuit breaker and cool-down(s).
+func (o *Options) CircuitBreaker() (failureThreshold int, coolDown int64) {
+	return o.circuitFailureThreshold, o.circuitCoolDown
+}
+
+// SetCircuitStateHook sets hook invoked when circuit state of endpoint changed.
+func (o *Options) SetCircuitStateHook(hook CircuitStateHook) *Options {
+	o.circuitStateHook = hook
+	o.reset()
+	return o
+}
+
+// CircuitStateHook returns hook invoked when circuit state of endpoint changed.
+func (o *Options) CircuitStateHook() CircuitStateHook {
+	return o.circuitStateHook
+}
+
+// Validate checks if http options are valid.
+func (o *Options) Validate() error {
+	if o.reqTimeout <= 0 && o.httpClient == nil {
+		return fmt.Errorf("request timeout(s) must be positive, got %d", o.reqTimeout)
+	}
+	timeouts := []struct {
+		name    string
+		timeout int64
+	}{
+		{name: "dial timeout", timeout: o.dialTimeout},
+		{name: "tls handshake timeout", timeout: o.tlsHandshakeTimeout},
+		{name: "idle connection timeout", timeout: o.idleConnTimeout},
+	}
+	for _, t := range timeouts {
+		if t.timeout < 0 {
+			return fmt.Errorf("%s(s) must not be negative, got %d", t.name, t.timeout)
+		}
+	}
+	if o.maxIdleConns < 0 || o.maxIdleConnsPerHost < 0 || o.maxConnsPerHost < 0 {
+		return fmt.Errorf("max connections must not be negative, got %d/%d/%d",
+			o.maxIdleConns, o.maxIdleConnsPerHost, o.maxConnsPerHost)
+	}
+	if (o.certFile == "") != (o.keyFile == "") {
+		return fmt.Errorf("client cert file and key file must be set together")
+	}
+	if o.minTLSVersion != 0 && (o.minTLSVersion < tls.VersionTLS10 || o.minTLSVersion > tls.VersionTLS13) {
+		return fmt.Errorf("unsupported min TLS version: %#x", o.minTLSVersion)
+	}
+	for key := range o.headers {
+		if key == "" {
+			return fmt.Errorf("header key must not be empty")
+		}
+	}
+	if o.circuitFailureThreshold < 0 {
+		return fmt.Errorf("circuit failure threshold must not be negative, got %d", o.circuitFailureThreshold)
+	}
+	if o.circuitFailureThreshold > 0 && o.circuitCoolDown <= 0 {
+		return fmt.Errorf("circuit cool-down(s) must be positive, got %d", o.circuitCoolDown)
+	}
+	return nil
+}
+
+// reset discards the shared HTTP client after setting changed, idle connections of discarded client are closed.
+func (o *Options) reset() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.client != nil {
+		o.client.CloseIdleConnections()
+	}
+	o.client = nil
+}
+
+// Clone returns a copy of setting(including static headers) without the shared HTTP client,
+// HTTP client of the copy is built with its own transport(connection pool).
+func (o *Options) Clone() *Options {
+	cloned := &Options{
+		reqTimeout:              o.reqTimeout,
+		tlsConfig:               o.tlsConfig,
+		caFile:                  o.caFile,
+		certFile:                o.certFile,
+		keyFile:                 o.keyFile,
+		serverName:              o.serverName,
+		minTLSVersion:           o.minTLSVersion,
+		dialTimeout:             o.dialTimeout,
+		keepAlive:               o.keepAlive,
+		tlsHandshakeTimeout:     o.tlsHandshakeTimeout,
+		maxIdleConns:            o.maxIdleConns,
+		maxIdleConnsPerHost:     o.maxIdleConnsPerHost,
+		maxConnsPerHost:         o.maxConnsPerHost,
+		idleConnTimeout:         o.idleConnTimeout,
+		enableHTTP2:             o.enableHTTP2,
+		proxy:                   o.proxy,
+		roundTripper:            o.roundTripper,
+		httpClient:              o.httpClient,
+		credentials:             o.credentials,
+		tracer:                  o.tracer,
+		circuitFailureThreshold: o.circuitFailureThreshold,
+		circuitCoolDown:         o.circuitCoolDown,
+		circuitStateHook:        o.circuitStateHook,
+	}
+	if o.headers != nil {
+		cloned.headers = make(map[string]string, len(o.headers))
+		for k, v := range o.headers {
+			cloned.headers[k] = v
+		}
+	}
+	return cloned
+}
+
 // DefaultOptions returns an Options with default.
 func DefaultOptions() *Options {
 	return &Options{
-		reqTimeout: 30, // set default request timeout
+		reqTimeout:          30, // set default request timeout
+		dialTimeout:         5,
+		keepAlive:           30,
+		tlsHandshakeTimeout: 5,
+		maxIdleConns:        100,
+		maxIdleConnsPerHost: 100,
+		idleConnTimeout:     90,
+		circuitCoolDown:     30,
 	}
 }
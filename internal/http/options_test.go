@@ -18,21 +18,162 @@
 package http
 
 import (
+	"context"
 	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/trace"
 )
 
+// nopTracer represents the tracer which creates no span.
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, _ string) (context.Context, trace.Span) {
+	return ctx, trace.SpanFromContext(ctx)
+}
+
+func (nopTracer) Inject(_ context.Context, _ http.Header) {}
+
+// newConnCountingServer creates a server which counts closed connections.
+func newConnCountingServer(closed *atomic.Int32) *httptest.Server {
+	svr := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`ok`))
+	}))
+	svr.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed.Add(1)
+		}
+	}
+	svr.Start()
+	return svr
+}
+
 func TestOptions(t *testing.T) {
 	assert.Equal(t, int64(30), DefaultOptions().ReqTimeout())
 	assert.Nil(t, DefaultOptions().TLSConfig())
 
 	assert.NotNil(t, DefaultOptions().HTTPClient())
 
+	opt0 := DefaultOptions()
+	cli := opt0.HTTPClient()
+	assert.Same(t, cli, opt0.HTTPClient())
+	opt0.SetReqTimeout(10)
+	assert.NotSame(t, cli, opt0.HTTPClient())
+
 	opt := DefaultOptions().
 		SetReqTimeout(60).
 		SetTLSConfig(&tls.Config{})
 	assert.NotNil(t, opt.TLSConfig())
 	assert.Equal(t, int64(60), opt.ReqTimeout())
 }
+
+func TestOptions_CloseIdleConnections(t *testing.T) {
+	var closed atomic.Int32
+	svr := newConnCountingServer(&closed)
+	defer svr.Close()
+
+	// all round trippers wrapped
+	opt := DefaultOptions().SetCredentialsProvider(NewBasicAuth("user", "pwd")).AddHeader("k", "v").
+		SetCircuitBreaker(3, 10).SetTracer(nopTracer{})
+	cli := opt.HTTPClient()
+	_, err := DoGet(context.TODO(), cli, svr.URL)
+	assert.NoError(t, err)
+	cli.CloseIdleConnections()
+	assert.Eventually(t, func() bool { return closed.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// idle connections of discarded client closed after setting changed
+	_, err = DoGet(context.TODO(), opt.HTTPClient(), svr.URL)
+	assert.NoError(t, err)
+	opt.SetReqTimeout(10)
+	assert.Eventually(t, func() bool { return closed.Load() == 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestOptions_Clone(t *testing.T) {
+	opt := DefaultOptions().SetReqTimeout(10).AddHeader("k1", "v1")
+	cloned := opt.Clone()
+	opt.AddHeader("k2", "v2").SetReqTimeout(20)
+	assert.Equal(t, int64(10), cloned.ReqTimeout())
+	assert.Equal(t, map[string]string{"k1": "v1"}, cloned.Headers())
+	// own transport
+	assert.NotSame(t, opt.HTTPClient(), cloned.HTTPClient())
+	assert.NotSame(t, opt.HTTPClient().Transport, cloned.HTTPClient().Transport)
+	assert.Nil(t, DefaultOptions().Clone().Headers())
+}
+
+func TestOptions_Transport(t *testing.T) {
+	opt := DefaultOptions()
+	assert.Equal(t, int64(5), opt.DialTimeout())
+	assert.Equal(t, int64(30), opt.KeepAlive())
+	assert.Equal(t, int64(5), opt.TLSHandshakeTimeout())
+	assert.Equal(t, 100, opt.MaxIdleConns())
+	assert.Equal(t, 100, opt.MaxIdleConnsPerHost())
+	assert.Equal(t, 0, opt.MaxConnsPerHost())
+	assert.Equal(t, int64(90), opt.IdleConnTimeout())
+	assert.False(t, opt.EnableHTTP2())
+	assert.Nil(t, opt.Proxy())
+
+	opt.SetDialTimeout(1).SetKeepAlive(2).SetTLSHandshakeTimeout(3).
+		SetMaxIdleConns(10).SetMaxIdleConnsPerHost(5).SetMaxConnsPerHost(8).
+		SetIdleConnTimeout(60).SetEnableHTTP2(true).SetProxy(http.ProxyFromEnvironment)
+	transport, ok := opt.HTTPClient().Transport.(*headerRoundTripper).next.(*http.Transport)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, transport.TLSHandshakeTimeout)
+	assert.Equal(t, 10, transport.MaxIdleConns)
+	assert.Equal(t, 5, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 8, transport.MaxConnsPerHost)
+	assert.Equal(t, 60*time.Second, transport.IdleConnTimeout)
+	assert.True(t, transport.ForceAttemptHTTP2)
+	assert.NotNil(t, transport.Proxy)
+	assert.Equal(t, int64(1), opt.DialTimeout())
+	assert.Equal(t, int64(2), opt.KeepAlive())
+	assert.NotNil(t, opt.Proxy())
+
+	rt := http.DefaultTransport
+	opt.SetRoundTripper(rt)
+	assert.Equal(t, rt, opt.RoundTripper())
+	assert.Equal(t, rt, opt.HTTPClient().Transport.(*headerRoundTripper).next)
+	assert.Equal(t, 30*time.Second, opt.HTTPClient().Timeout)
+
+	cli := &http.Client{Timeout: time.Minute}
+	opt.SetHTTPClient(cli)
+	assert.Equal(t, time.Minute, opt.HTTPClient().Timeout)
+	assert.Equal(t, http.DefaultTransport, opt.HTTPClient().Transport.(*headerRoundTripper).next)
+}
+
+func TestOptions_Validate(t *testing.T) {
+	assert.NoError(t, DefaultOptions().Validate())
+	assert.NoError(t, DefaultOptions().SetReqTimeout(0).SetHTTPClient(&http.Client{}).Validate())
+	assert.NoError(t, DefaultOptions().SetCircuitBreaker(0, 0).Validate())
+	assert.NoError(t, DefaultOptions().SetClientCertFile("cert.pem", "key.pem").
+		SetMinTLSVersion(tls.VersionTLS12).SetKeepAlive(-1).Validate())
+
+	cases := []struct {
+		name string
+		opt  *Options
+	}{
+		{name: "zero request timeout", opt: DefaultOptions().SetReqTimeout(0)},
+		{name: "negative dial timeout", opt: DefaultOptions().SetDialTimeout(-1)},
+		{name: "negative tls handshake timeout", opt: DefaultOptions().SetTLSHandshakeTimeout(-1)},
+		{name: "negative idle conn timeout", opt: DefaultOptions().SetIdleConnTimeout(-1)},
+		{name: "negative max idle conns", opt: DefaultOptions().SetMaxIdleConns(-1)},
+		{name: "negative max conns per host", opt: DefaultOptions().SetMaxConnsPerHost(-1)},
+		{name: "cert file without key file", opt: DefaultOptions().SetClientCertFile("cert.pem", "")},
+		{name: "unsupported tls version", opt: DefaultOptions().SetMinTLSVersion(0x0200)},
+		{name: "empty header key", opt: DefaultOptions().AddHeader("", "v")},
+		{name: "negative circuit failure threshold", opt: DefaultOptions().SetCircuitBreaker(-1, 10)},
+		{name: "zero circuit cool-down", opt: DefaultOptions().SetCircuitBreaker(3, 0)},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.opt.Validate())
+		})
+	}
+}
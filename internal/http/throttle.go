@@ -0,0 +1,65 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRetryAfter represents the delay used if server throttles request without valid Retry-After header.
+const DefaultRetryAfter = time.Second
+
+// maxRetryAfterSeconds represents the maximum seconds of Retry-After which can be converted to duration.
+const maxRetryAfterSeconds = math.MaxInt64 / int64(time.Second)
+
+// RetryAfter returns the delay requested by server if response is throttled(429, or 503 with Retry-After header),
+// Retry-After header is parsed as seconds or HTTP date, DefaultRetryAfter is used if header missing or invalid.
+func RetryAfter(resp *http.Response, now time.Time) (delay time.Duration, throttled bool) {
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusServiceUnavailable && value != "":
+	default:
+		return 0, false
+	}
+	if value == "" {
+		return DefaultRetryAfter, true
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+		if seconds < 0 {
+			return DefaultRetryAfter, true
+		}
+		// avoid duration overflow
+		if seconds > maxRetryAfterSeconds {
+			seconds = maxRetryAfterSeconds
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay = date.Sub(now); delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return DefaultRetryAfter, true
+}
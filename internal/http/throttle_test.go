@@ -0,0 +1,64 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name       string
+		status     int
+		retryAfter string
+		delay      time.Duration
+		throttled  bool
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "server error", status: http.StatusInternalServerError, retryAfter: "10"},
+		{name: "unavailable without retry-after", status: http.StatusServiceUnavailable},
+		{name: "unavailable with retry-after", status: http.StatusServiceUnavailable, retryAfter: "10", delay: 10 * time.Second, throttled: true},
+		{name: "too many requests", status: http.StatusTooManyRequests, delay: DefaultRetryAfter, throttled: true},
+		{name: "seconds", status: http.StatusTooManyRequests, retryAfter: " 120 ", delay: 2 * time.Minute, throttled: true},
+		{name: "huge seconds", status: http.StatusTooManyRequests, retryAfter: "9223372036854775807",
+			delay: time.Duration(maxRetryAfterSeconds) * time.Second, throttled: true},
+		{name: "out of range seconds", status: http.StatusTooManyRequests, retryAfter: "99999999999999999999",
+			delay: time.Duration(maxRetryAfterSeconds) * time.Second, throttled: true},
+		{name: "negative seconds", status: http.StatusTooManyRequests, retryAfter: "-1", delay: DefaultRetryAfter, throttled: true},
+		{name: "http date", status: http.StatusTooManyRequests, retryAfter: "Wed, 01 Mar 2023 10:00:30 GMT", delay: 30 * time.Second, throttled: true},
+		{name: "past http date", status: http.StatusTooManyRequests, retryAfter: "Wed, 01 Mar 2023 09:00:00 GMT", throttled: true},
+		{name: "invalid", status: http.StatusTooManyRequests, retryAfter: "abc", delay: DefaultRetryAfter, throttled: true},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			delay, throttled := RetryAfter(resp, now)
+			assert.Equal(t, tt.throttled, throttled)
+			assert.Equal(t, tt.delay, delay)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileCheckInterval represents the minimum interval of checking if PEM files modified,
+// avoids stat files for each request/handshake.
+const fileCheckInterval = 10 * time.Second
+
+// fileState represents the state of file for checking if file modified.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// certificates represents the CA bundle/client certificate loaded from PEM files,
+// reloads files when they are modified(checked every fileCheckInterval), so certificates can be rotated without recreating client.
+type certificates struct {
+	caFile   string
+	certFile string
+	keyFile  string
+
+	caPool        *x509.CertPool
+	cert          *tls.Certificate
+	caCheckedAt   time.Time // last time of checking CA bundle file
+	certCheckedAt time.Time // last time of checking cert/key files
+	states        map[string]fileState
+	mutex         sync.Mutex
+}
+
+// newCertificates creates certificates with PEM file paths.
+func newCertificates(caFile, certFile, keyFile string) *certificates {
+	return &certificates{
+		caFile:   caFile,
+		certFile: certFile,
+		keyFile:  keyFile,
+		states:   make(map[string]fileState),
+	}
+}
+
+// modified checks if files modified since last loaded, returns the latest states.
+func (c *certificates) modified(files ...string) (bool, map[string]fileState, error) {
+	changed := false
+	states := make(map[string]fileState, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, nil, err
+		}
+		state := fileState{modTime: info.ModTime(), size: info.Size()}
+		states[file] = state
+		if old, ok := c.states[file]; !ok || old != state {
+			changed = true
+		}
+	}
+	return changed, states, nil
+}
+
+// clientCertificate returns client certificate, reloads it if cert/key files modified.
+func (c *certificates) clientCertificate(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := nowFn()
+	if c.cert != nil && now.Sub(c.certCheckedAt) < fileCheckInterval {
+		return c.cert, nil
+	}
+	c.certCheckedAt = now
+
+	changed, states, err := c.modified(c.certFile, c.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	if changed || c.cert == nil {
+		cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		c.cert = &cert
+		for file, state := range states {
+			c.states[file] = state
+		}
+	}
+	return c.cert, nil
+}
+
+// rootCAs returns CA pool, reloads it if CA bundle file modified.
+func (c *certificates) rootCAs() (*x509.CertPool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := nowFn()
+	if c.caPool != nil && now.Sub(c.caCheckedAt) < fileCheckInterval {
+		return c.caPool, nil
+	}
+	c.caCheckedAt = now
+
+	changed, states, err := c.modified(c.caFile)
+	if err != nil {
+		return nil, err
+	}
+	if changed || c.caPool == nil {
+		pem, err := os.ReadFile(c.caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificate found in CA bundle file: %s", c.caFile)
+		}
+		c.caPool = pool
+		c.states[c.caFile] = states[c.caFile]
+	}
+	return c.caPool, nil
+}
+
+// caReloadTransport represents the transport which verifies server certificate with CA pool loaded from CA bundle file,
+// transport is rebuilt with reloaded CA pool when file modified, so CA can be rotated without recreating client.
+// Server certificate is verified by standard TLS verification(RootCAs) against the dialed host or server name.
+type caReloadTransport struct {
+	certs *certificates
+	base  *http.Transport // template of transport, cloned with CA pool
+
+	pool      *x509.CertPool
+	transport *http.Transport
+	mutex     sync.Mutex
+}
+
+// newCAReloadTransport creates a caReloadTransport with template transport(TLS configuration required).
+func newCAReloadTransport(certs *certificates, base *http.Transport) *caReloadTransport {
+	return &caReloadTransport{
+		certs: certs,
+		base:  base,
+	}
+}
+
+// RoundTrip executes a single HTTP transaction with transport of latest CA pool.
+func (t *caReloadTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport, err := t.current()
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+	return transport.RoundTrip(req)
+}
+
+// CloseIdleConnections closes idle connections of current transport.
+func (t *caReloadTransport) CloseIdleConnections() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.transport != nil {
+		t.transport.CloseIdleConnections()
+	}
+}
+
+// current returns the transport of latest CA pool, rebuilds transport if CA bundle file modified.
+func (t *caReloadTransport) current() (*http.Transport, error) {
+	pool, err := t.certs.rootCAs()
+	if err != nil {
+		return nil, err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if pool != t.pool {
+		old := t.transport
+		transport := t.base.Clone()
+		transport.TLSClientConfig.RootCAs = pool
+		t.pool, t.transport = pool, transport
+		if old != nil {
+			old.CloseIdleConnections()
+		}
+	}
+	return t.transport, nil
+}
@@ -0,0 +1,255 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCA represents a CA for issuing client certificates in test.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+// issue issues a client certificate, writes cert/key PEM files.
+func (ca *testCA) issue(t *testing.T, serial int64, certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+}
+
+// issueServer issues a server certificate for DNS names/IPs.
+func (ca *testCA) issueServer(t *testing.T, dnsNames []string, ips []net.IP) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	assert.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func newTLSServer(cert tls.Certificate) *httptest.Server {
+	svr := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// handshake for each request
+		w.Header().Set("Connection", "close")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	svr.Config.ErrorLog = log.New(io.Discard, "", 0)
+	svr.TLS = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	svr.StartTLS()
+	return svr
+}
+
+func TestOptions_CAFile_VerifyHost(t *testing.T) {
+	advance := mockFileCheckClock(t)
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", ca.cert.Raw)
+
+	// certificate signed by CA for other host, served on IP
+	otherSvr := newTLSServer(ca.issueServer(t, []string{"other.example"}, nil))
+	defer otherSvr.Close()
+	_, err := DoGet(context.TODO(), DefaultOptions().SetCAFile(caFile).HTTPClient(), otherSvr.URL)
+	assert.Error(t, err)
+	// server name overrides dialed host
+	resp, err := DoGet(context.TODO(), DefaultOptions().SetCAFile(caFile).SetServerName("other.example").HTTPClient(), otherSvr.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(resp))
+
+	// certificate signed by CA for IP
+	svr := newTLSServer(ca.issueServer(t, nil, []net.IP{net.ParseIP("127.0.0.1")}))
+	defer svr.Close()
+	cli := DefaultOptions().SetCAFile(caFile).HTTPClient()
+	resp, err = DoGet(context.TODO(), cli, svr.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(resp))
+
+	// certificate signed by unknown CA
+	unknownSvr := newTLSServer(newTestCA(t).issueServer(t, nil, []net.IP{net.ParseIP("127.0.0.1")}))
+	defer unknownSvr.Close()
+	_, err = DoGet(context.TODO(), cli, unknownSvr.URL)
+	assert.Error(t, err)
+
+	// rotate CA bundle on disk, not checked within interval
+	writePEM(t, caFile, "CERTIFICATE", newTestCA(t).cert.Raw)
+	_, err = DoGet(context.TODO(), cli, svr.URL)
+	assert.NoError(t, err)
+	advance()
+	_, err = DoGet(context.TODO(), cli, svr.URL)
+	assert.Error(t, err)
+	writePEM(t, caFile, "CERTIFICATE", ca.cert.Raw)
+	advance()
+	_, err = DoGet(context.TODO(), cli, svr.URL)
+	assert.NoError(t, err)
+	cli.CloseIdleConnections()
+}
+
+// mockFileCheckClock makes PEM files checked on next request after advanced.
+func mockFileCheckClock(t *testing.T) (advance func()) {
+	var clock atomic.Int64
+	clock.Store(time.Now().UnixNano())
+	nowFn = func() time.Time {
+		return time.Unix(0, clock.Load())
+	}
+	t.Cleanup(func() {
+		nowFn = time.Now
+	})
+	return func() {
+		clock.Add(int64(fileCheckInterval))
+	}
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte) {
+	assert.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	// make sure modification time changed
+	modTime := time.Now().Add(time.Duration(time.Now().UnixNano()%1000) * time.Second)
+	assert.NoError(t, os.Chtimes(file, modTime, modTime))
+}
+
+func TestOptions_TLSFiles(t *testing.T) {
+	advance := mockFileCheckClock(t)
+	ca := newTestCA(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	svr := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// handshake for each request
+		w.Header().Set("Connection", "close")
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].SerialNumber.String()))
+	}))
+	svr.Config.ErrorLog = log.New(io.Discard, "", 0)
+	svr.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+	svr.StartTLS()
+	defer svr.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	writePEM(t, caFile, "CERTIFICATE", svr.Certificate().Raw)
+	ca.issue(t, 100, certFile, keyFile)
+
+	opt := DefaultOptions().SetCAFile(caFile).SetClientCertFile(certFile, keyFile).
+		SetServerName("example.com").SetMinTLSVersion(tls.VersionTLS12)
+	assert.Equal(t, caFile, opt.CAFile())
+	c, k := opt.ClientCertFile()
+	assert.Equal(t, certFile, c)
+	assert.Equal(t, keyFile, k)
+	assert.Equal(t, "example.com", opt.ServerName())
+	assert.Equal(t, uint16(tls.VersionTLS12), opt.MinTLSVersion())
+
+	cli := opt.HTTPClient()
+	resp, err := DoGet(context.TODO(), cli, svr.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "100", string(resp))
+
+	// rotate client certificate on disk, not checked within interval
+	ca.issue(t, 200, certFile, keyFile)
+	resp, err = DoGet(context.TODO(), cli, svr.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "100", string(resp))
+	advance()
+	resp, err = DoGet(context.TODO(), cli, svr.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "200", string(resp))
+
+	// server name mismatch
+	opt.SetServerName("lindb.io")
+	_, err = DoGet(context.TODO(), opt.HTTPClient(), svr.URL)
+	assert.Error(t, err)
+
+	// invalid CA bundle
+	opt.SetServerName("")
+	writePEM(t, caFile, "UNKNOWN", []byte("abc"))
+	_, err = DoGet(context.TODO(), opt.HTTPClient(), svr.URL)
+	assert.Error(t, err)
+
+	// CA bundle/client certificate not exist
+	_, err = DoGet(context.TODO(), DefaultOptions().SetCAFile(filepath.Join(dir, "no.pem")).HTTPClient(), svr.URL)
+	assert.Error(t, err)
+	_, err = DoGet(context.TODO(), DefaultOptions().SetTLSConfig(&tls.Config{RootCAs: clientCAs}).
+		SetClientCertFile(caFile, keyFile).HTTPClient(), svr.URL)
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/lindb/client_go/trace"
+)
+
+// authRoundTripper represents the round tripper which sets credentials for each request,
+// retries request once if unauthorized and credentials refreshed.
+type authRoundTripper struct {
+	next        http.RoundTripper
+	credentials CredentialsProvider
+}
+
+// RoundTrip executes a single HTTP transaction with credentials.
+func (rt *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.roundTrip(req, req.Body)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.GetBody == nil && req.Body != nil && req.Body != http.NoBody {
+		// request body cannot be replayed
+		return resp, nil
+	}
+	if !rt.credentials.Invalidate() {
+		return resp, nil
+	}
+	// discard unauthorized response, retry with new credentials
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	body := req.Body
+	if req.GetBody != nil {
+		if body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return rt.roundTrip(req, body)
+}
+
+// CloseIdleConnections closes idle connections of next round tripper if supported.
+func (rt *authRoundTripper) CloseIdleConnections() {
+	closeIdleConnections(rt.next)
+}
+
+// roundTrip sends a copy of request with credentials and given body.
+func (rt *authRoundTripper) roundTrip(req *http.Request, body io.ReadCloser) (*http.Response, error) {
+	authorization, err := rt.credentials.Authorization(req.Context())
+	if err != nil {
+		if body != nil {
+			_ = body.Close()
+		}
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	r.Header.Set("Authorization", authorization)
+	return rt.next.RoundTrip(r)
+}
+
+// tracingRoundTripper represents the round tripper which injects trace context into request headers,
+// records response status code into span carried by request context.
+type tracingRoundTripper struct {
+	next   http.RoundTripper
+	tracer trace.Tracer
+}
+
+// RoundTrip executes a single HTTP transaction with trace context.
+func (rt *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	r := req.Clone(ctx)
+	rt.tracer.Inject(ctx, r.Header)
+	resp, err := rt.next.RoundTrip(r)
+	if err == nil {
+		trace.SpanFromContext(ctx).SetAttributes(trace.Int(trace.AttrStatusCode, resp.StatusCode))
+	}
+	return resp, err
+}
+
+// CloseIdleConnections closes idle connections of next round tripper if supported.
+func (rt *tracingRoundTripper) CloseIdleConnections() {
+	closeIdleConnections(rt.next)
+}
+
+// closeIdleConnections closes idle connections of round tripper if it supports CloseIdleConnections,
+// http.Client only forwards CloseIdleConnections to its outermost round tripper.
+func closeIdleConnections(rt http.RoundTripper) {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+	if c, ok := rt.(closeIdler); ok {
+		c.CloseIdleConnections()
+	}
+}
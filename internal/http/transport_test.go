@@ -0,0 +1,83 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthRoundTripper(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer svr.Close()
+
+	count := 0
+	opt := DefaultOptions().SetCredentialsProvider(NewRefreshingToken(func(_ context.Context) (string, error) {
+		count++
+		return fmt.Sprintf("token-%d", count), nil
+	}))
+	assert.NotNil(t, opt.CredentialsProvider())
+
+	// token refreshed after unauthorized, body replayed
+	resp, err := DoPut(context.TODO(), opt.HTTPClient(), svr.URL, []byte("data"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), resp)
+	assert.Equal(t, 2, count)
+
+	// request body cannot be replayed
+	req, _ := http.NewRequestWithContext(context.TODO(), http.MethodPut, svr.URL, io.NopCloser(bytes.NewReader([]byte("data"))))
+	opt.CredentialsProvider().Invalidate()
+	resp0, err := opt.HTTPClient().Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp0.StatusCode)
+	_ = resp0.Body.Close()
+
+	// static credentials cannot be refreshed
+	opt.SetCredentialsProvider(NewBasicAuth("admin", "admin"))
+	_, err = DoGet(context.TODO(), opt.HTTPClient(), svr.URL)
+	assert.Error(t, err)
+
+	// fetch token failure
+	opt.SetCredentialsProvider(NewRefreshingToken(func(_ context.Context) (string, error) {
+		return "", fmt.Errorf("err")
+	}))
+	_, err = DoPut(context.TODO(), opt.HTTPClient(), svr.URL, []byte("data"))
+	assert.Error(t, err)
+
+	// custom http client wrapped
+	cli := &http.Client{}
+	opt.SetHTTPClient(cli).SetCredentialsProvider(NewBearerToken("token-2"))
+	assert.NotSame(t, cli, opt.HTTPClient())
+	resp, err = DoPut(context.TODO(), opt.HTTPClient(), svr.URL, []byte("data"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), resp)
+}
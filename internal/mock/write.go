@@ -0,0 +1,134 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mock
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/lindb/client_go/api"
+)
+
+// Write represents the write client which records added points in memory, used for testing.
+type Write struct {
+	points    []*api.Point
+	options   *api.WriteOptions
+	paused    bool
+	closed    bool
+	errCh     chan error
+	mutex     sync.Mutex
+	closeOnce sync.Once
+}
+
+// NewWrite creates a Write with default write options.
+func NewWrite() *Write {
+	return &Write{
+		options: api.DefaultWriteOptions(),
+		errCh:   make(chan error),
+	}
+}
+
+// AddPoint records point, point added after closed is dropped.
+func (w *Write) AddPoint(_ context.Context, point *api.Point) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if !w.closed {
+		w.points = append(w.points, point)
+	}
+}
+
+// AddLineProtocol parses InfluxDB line protocol from reader by default parser, then records points.
+func (w *Write) AddLineProtocol(ctx context.Context, r io.Reader) error {
+	return api.NewLineProtocolParser().WriteTo(ctx, w, r)
+}
+
+// Points returns the points added.
+func (w *Write) Points() []*api.Point {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return append([]*api.Point(nil), w.points...)
+}
+
+// Errors returns the error chan, which is closed after closed.
+func (w *Write) Errors() <-chan error {
+	return w.errCh
+}
+
+// Update applies modify on a copy of current options, returns error if new options are invalid.
+func (w *Write) Update(modify func(opt *api.WriteOptions)) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	opt := w.options.Clone()
+	modify(opt)
+	if err := opt.Validate(); err != nil {
+		return err
+	}
+	w.options = opt
+	return nil
+}
+
+// Options returns the current write options.
+func (w *Write) Options() *api.WriteOptions {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.options
+}
+
+// Pause marks write client paused.
+func (w *Write) Pause() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.paused = true
+}
+
+// Resume marks write client resumed.
+func (w *Write) Resume() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.paused = false
+}
+
+// Paused returns whether write client is paused.
+func (w *Write) Paused() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.paused
+}
+
+// Stats returns empty statistics, no point is throttled.
+func (w *Write) Stats() api.WriteStats {
+	return api.WriteStats{}
+}
+
+// Close marks write client closed, then closes error chan.
+func (w *Write) Close() {
+	w.closeOnce.Do(func() {
+		w.mutex.Lock()
+		w.closed = true
+		w.mutex.Unlock()
+		close(w.errCh)
+	})
+}
@@ -0,0 +1,107 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ratelimit
+
+import (
+	"time"
+)
+
+// for testing
+var (
+	nowFn = time.Now
+)
+
+// Limiter represents the token bucket rate limiter, bucket is refilled by limit tokens per second,
+// burst size is limit(tokens of 1 second). Limiter is not safe for concurrent use.
+type Limiter struct {
+	limit  float64 // tokens per second, 0 means no limit
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates a Limiter with tokens per second, 0 means no limit.
+func NewLimiter(limit int) *Limiter {
+	return &Limiter{
+		limit:  float64(limit),
+		tokens: float64(limit),
+		last:   nowFn(),
+	}
+}
+
+// SetLimit changes tokens per second, 0 means no limit.
+func (l *Limiter) SetLimit(limit int) {
+	if float64(limit) == l.limit {
+		return
+	}
+	l.refill()
+	if l.limit <= 0 {
+		// no limit before, starts with full bucket
+		l.tokens = float64(limit)
+	}
+	l.limit = float64(limit)
+	if l.tokens > l.limit {
+		l.tokens = l.limit
+	}
+}
+
+// Limit returns tokens per second, 0 means no limit.
+func (l *Limiter) Limit() int {
+	return int(l.limit)
+}
+
+// Ready returns if n tokens are available without waiting,
+// n is capped by burst size so that request larger than burst can be sent when bucket is full.
+func (l *Limiter) Ready(n int) bool {
+	if l.limit <= 0 {
+		return true
+	}
+	l.refill()
+	return l.tokens >= minFloat(float64(n), l.limit)
+}
+
+// Take consumes n tokens(bucket may become negative), returns the duration to wait before tokens are available.
+func (l *Limiter) Take(n int) time.Duration {
+	if l.limit <= 0 {
+		return 0
+	}
+	l.refill()
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.limit * float64(time.Second))
+}
+
+// refill adds tokens elapsed since last refill, tokens are capped by burst size.
+func (l *Limiter) refill() {
+	now := nowFn()
+	elapsed := now.Sub(l.last)
+	l.last = now
+	if elapsed <= 0 || l.limit <= 0 {
+		return
+	}
+	l.tokens = minFloat(l.tokens+elapsed.Seconds()*l.limit, l.limit)
+}
+
+// minFloat returns the smaller one of a and b.
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,68 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	nowFn = func() time.Time { return now }
+	defer func() {
+		nowFn = time.Now
+	}()
+
+	l := NewLimiter(100)
+	assert.Equal(t, 100, l.Limit())
+	assert.True(t, l.Ready(100))
+	assert.Equal(t, time.Duration(0), l.Take(60))
+	assert.False(t, l.Ready(50))
+	assert.True(t, l.Ready(40))
+	// bucket negative, wait for refill
+	assert.Equal(t, 200*time.Millisecond, l.Take(60))
+
+	now = now.Add(200 * time.Millisecond)
+	assert.False(t, l.Ready(1))
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, l.Ready(50))
+	// bucket capped by burst
+	now = now.Add(time.Hour)
+	assert.Equal(t, time.Duration(0), l.Take(100))
+	assert.False(t, l.Ready(1))
+	// request larger than burst is ready if bucket full
+	now = now.Add(time.Second)
+	assert.True(t, l.Ready(1_000))
+	assert.Equal(t, 9*time.Second, l.Take(1_000))
+
+	// change limit
+	l.SetLimit(100) // same limit, ignore it
+	l.SetLimit(0)
+	assert.Equal(t, 0, l.Limit())
+	assert.True(t, l.Ready(1_000_000))
+	assert.Equal(t, time.Duration(0), l.Take(1_000_000))
+	l.SetLimit(10)
+	assert.True(t, l.Ready(10))
+	assert.Equal(t, time.Duration(0), l.Take(10))
+	assert.Equal(t, time.Second, l.Take(10))
+	l.SetLimit(5)
+	assert.Equal(t, 5, l.Limit())
+}
@@ -0,0 +1,306 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jsonpoint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lindb/client_go/api"
+)
+
+// LineError represents the error of decoding line in JSON Lines stream.
+type LineError struct {
+	Line int // line number, starts from 1
+	Err  error
+}
+
+// Error returns the error message with line number.
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder represents the decoder which converts JSON documents into points by schema.
+type Decoder struct {
+	schema Schema
+	fields []string // sorted field keys
+}
+
+// NewDecoder creates a Decoder with schema, returns error if schema is invalid.
+func NewDecoder(schema Schema) (*Decoder, error) {
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	fields := make([]string, 0, len(schema.Fields))
+	for key := range schema.Fields {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+	return &Decoder{schema: schema, fields: fields}, nil
+}
+
+// Decode decodes single JSON object into point.
+func (d *Decoder) Decode(data []byte) (*api.Point, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, errors.New("expect JSON object")
+	}
+	return d.PointFromMap(doc)
+}
+
+// PointFromMap converts map(e.g. decoded JSON object) into point.
+func (d *Decoder) PointFromMap(doc map[string]any) (*api.Point, error) {
+	metric := d.schema.Metric
+	if metric == "" {
+		v, ok := lookup(doc, d.schema.MetricKey)
+		if !ok {
+			return nil, fmt.Errorf("metric %q not found", d.schema.MetricKey)
+		}
+		metric, ok = v.(string)
+		if !ok || metric == "" {
+			return nil, fmt.Errorf("metric %q must be non-empty string, got %v", d.schema.MetricKey, v)
+		}
+	}
+	point := api.NewPoint(metric)
+
+	namespace := d.schema.Namespace
+	if namespace == "" && d.schema.NamespaceKey != "" {
+		if v, ok := lookup(doc, d.schema.NamespaceKey); ok {
+			if namespace, ok = v.(string); !ok {
+				return nil, fmt.Errorf("namespace %q must be string, got %v", d.schema.NamespaceKey, v)
+			}
+		}
+	}
+	point.SetNamespace(namespace)
+
+	if d.schema.TimestampKey != "" {
+		if v, ok := lookup(doc, d.schema.TimestampKey); ok {
+			timestamp, err := parseTimestamp(v, d.schema.TimestampFormat)
+			if err != nil {
+				return nil, fmt.Errorf("timestamp %q: %w", d.schema.TimestampKey, err)
+			}
+			point.SetTimestamp(timestamp)
+		}
+	}
+
+	for _, key := range d.schema.Tags {
+		v, ok := lookup(doc, key)
+		if !ok || v == nil {
+			continue
+		}
+		value, err := formatTag(v)
+		if err != nil {
+			return nil, fmt.Errorf("tag %q: %w", key, err)
+		}
+		point.AddTag(key, value)
+	}
+
+	for _, key := range d.fields {
+		v, ok := lookup(doc, key)
+		if !ok || v == nil {
+			continue
+		}
+		value, err := parseNumber(v)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", key, err)
+		}
+		point.AddField(d.schema.Fields[key].NewField(key, value))
+	}
+	if len(point.Fields()) == 0 {
+		return nil, errors.New("no field found")
+	}
+	return point, nil
+}
+
+// WriteLines decodes JSON Lines stream(blank line skipped) into points, then adds points into write client,
+// onError is invoked with *LineError for each line failed to decode(nil to ignore),
+// returns the number of points written and error if failed to read stream or context done.
+func (d *Decoder) WriteLines(ctx context.Context, w api.Write, r io.Reader, onError func(err *LineError)) (int, error) {
+	reader := bufio.NewReader(r)
+	written := 0
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return written, err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			point, decodeErr := d.Decode(data)
+			if decodeErr != nil {
+				if onError != nil {
+					onError(&LineError{Line: line, Err: decodeErr})
+				}
+			} else {
+				w.AddPoint(ctx, point)
+				written++
+			}
+		}
+		if err != nil {
+			// EOF
+			return written, nil
+		}
+	}
+}
+
+// lookup returns the value of dot-separated key path in nested objects.
+func lookup(doc map[string]any, key string) (any, bool) {
+	if v, ok := doc[key]; ok {
+		return v, true
+	}
+	head, rest, ok := strings.Cut(key, ".")
+	if !ok {
+		return nil, false
+	}
+	child, ok := doc[head].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return lookup(child, rest)
+}
+
+// parseNumber parses number or numeric string as float64.
+func parseNumber(v any) (float64, error) {
+	var (
+		f   float64
+		err error
+	)
+	switch value := v.(type) {
+	case json.Number:
+		f, err = value.Float64()
+	case float64:
+		f = value
+	case int:
+		f = float64(value)
+	case int64:
+		f = float64(value)
+	case string:
+		f, err = strconv.ParseFloat(value, 64)
+	default:
+		return 0, fmt.Errorf("expect number, got %T", v)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("expect finite number, got %v", f)
+	}
+	return f, nil
+}
+
+// formatTag formats string/number/bool as tag value.
+func formatTag(v any) (string, error) {
+	switch value := v.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(value), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	default:
+		return "", fmt.Errorf("expect string/number/bool, got %T", v)
+	}
+}
+
+// parseTimestamp parses timestamp by format.
+func parseTimestamp(v any, format string) (time.Time, error) {
+	if s, ok := v.(string); ok && !isUnixFormat(format) {
+		if format == "" {
+			format = time.RFC3339Nano
+		}
+		return time.Parse(format, s)
+	}
+	n, err := parseNumber(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// keep precision of integer timestamp
+	i, isInt := parseInt(v)
+	if !isInt {
+		i = int64(n)
+	}
+	switch format {
+	case TimestampUnix:
+		sec, frac := math.Modf(n)
+		return time.Unix(int64(sec), int64(frac*1e9)), nil
+	case TimestampUnixMicro:
+		return time.UnixMicro(i), nil
+	case TimestampUnixNano:
+		return time.Unix(0, i), nil
+	case TimestampUnixMilli, "":
+		return time.UnixMilli(i), nil
+	default:
+		return time.Time{}, fmt.Errorf("expect string for time layout %q, got %v", format, v)
+	}
+}
+
+// parseInt parses integer number or integer string, returns false if not integer.
+func parseInt(v any) (int64, bool) {
+	var s string
+	switch value := v.(type) {
+	case json.Number:
+		s = value.String()
+	case string:
+		s = value
+	case int64:
+		return value, true
+	case int:
+		return int64(value), true
+	default:
+		return 0, false
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	return i, err == nil
+}
+
+// isUnixFormat checks if timestamp format is unix(s/ms/us/ns).
+func isUnixFormat(format string) bool {
+	switch format {
+	case TimestampUnix, TimestampUnixMilli, TimestampUnixMicro, TimestampUnixNano:
+		return true
+	default:
+		return false
+	}
+}
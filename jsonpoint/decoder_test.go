@@ -0,0 +1,172 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jsonpoint
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/api"
+	"github.com/lindb/client_go/internal/mock"
+)
+
+func TestNewDecoder(t *testing.T) {
+	d, err := NewDecoder(Schema{})
+	assert.Error(t, err)
+	assert.Nil(t, d)
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	d, err := NewDecoder(Schema{
+		MetricKey:    "name",
+		NamespaceKey: "ns",
+		TimestampKey: "ts",
+		Tags:         []string{"host", "req.path", "status", "tls", "missing"},
+		Fields: map[string]FieldType{
+			"req.latency": Last,
+			"bytes":       Sum,
+			"count":       Sum,
+			"missing":     Max,
+		},
+	})
+	assert.NoError(t, err)
+
+	p, err := d.Decode([]byte(`{"name":"http","ns":"prod","ts":1700000000123,"host":"h1","status":200,"tls":true,
+		"req":{"path":"/api","latency":12.5},"bytes":"1024","count":null}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "http", p.MetricName())
+	assert.Equal(t, "prod", p.Namespace())
+	assert.Equal(t, time.UnixMilli(1_700_000_000_123), p.Timestamp())
+	assert.Equal(t, map[string]string{"host": "h1", "req.path": "/api", "status": "200", "tls": "true"}, p.Tags())
+	assert.Equal(t, []api.Field{api.NewSum("bytes", 1024), api.NewLast("req.latency", 12.5)}, p.Fields())
+
+	cases := []struct {
+		name string
+		doc  string
+		err  string
+	}{
+		{name: "invalid json", doc: `{`, err: "unexpected EOF"},
+		{name: "not object", doc: `null`, err: "expect JSON object"},
+		{name: "metric not found", doc: `{"bytes":1}`, err: `metric "name" not found`},
+		{name: "metric not string", doc: `{"name":1,"bytes":1}`, err: `metric "name" must be non-empty string`},
+		{name: "namespace not string", doc: `{"name":"http","ns":1,"bytes":1}`, err: `namespace "ns" must be string`},
+		{name: "invalid timestamp", doc: `{"name":"http","ts":"now","bytes":1}`, err: `timestamp "ts"`},
+		{name: "invalid tag", doc: `{"name":"http","host":{},"bytes":1}`, err: `tag "host"`},
+		{name: "invalid field", doc: `{"name":"http","bytes":"a"}`, err: `field "bytes"`},
+		{name: "invalid field type", doc: `{"name":"http","bytes":[1]}`, err: `field "bytes": expect number`},
+		{name: "no field", doc: `{"name":"http"}`, err: "no field found"},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := d.Decode([]byte(tt.doc))
+			assert.Nil(t, p)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestDecoder_PointFromMap(t *testing.T) {
+	d, err := NewDecoder(Schema{
+		Metric:    "cpu",
+		Namespace: "ns",
+		Tags:      []string{"host", "core"},
+		Fields:    map[string]FieldType{"load": Sum, "usage": Last},
+	})
+	assert.NoError(t, err)
+	p, err := d.PointFromMap(map[string]any{"host": "h1", "core": 2, "load": 1.5, "usage": int64(80)})
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu", p.MetricName())
+	assert.Equal(t, "ns", p.Namespace())
+	assert.Equal(t, map[string]string{"host": "h1", "core": "2"}, p.Tags())
+	assert.Equal(t, []api.Field{api.NewSum("load", 1.5), api.NewLast("usage", 80)}, p.Fields())
+
+	_, err = d.PointFromMap(map[string]any{"load": "NaN"})
+	assert.ErrorContains(t, err, "expect finite number")
+}
+
+func TestParseTimestamp(t *testing.T) {
+	cases := []struct {
+		format string
+		v      any
+		expect time.Time
+		err    bool
+	}{
+		{v: "2023-11-14T22:13:20.5Z", expect: time.Date(2023, 11, 14, 22, 13, 20, 5e8, time.UTC)},
+		{format: "2006-01-02 15:04:05", v: "2023-11-14 22:13:20", expect: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{format: "2006-01-02", v: 1, err: true},
+		{v: 1_700_000_000_123.0, expect: time.UnixMilli(1_700_000_000_123)},
+		{format: TimestampUnix, v: "1700000000.5", expect: time.Unix(1_700_000_000, 5e8)},
+		{format: TimestampUnixMilli, v: int64(1_700_000_000_123), expect: time.UnixMilli(1_700_000_000_123)},
+		{format: TimestampUnixMicro, v: 1_700_000_000_000_001.0, expect: time.UnixMicro(1_700_000_000_000_001)},
+		{format: TimestampUnixNano, v: "1700000000000000001", expect: time.Unix(0, 1_700_000_000_000_000_001)},
+		{format: TimestampUnix, v: true, err: true},
+	}
+	for _, tt := range cases {
+		ts, err := parseTimestamp(tt.v, tt.format)
+		if tt.err {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.True(t, tt.expect.Equal(ts), "format: %s, value: %v, got: %s", tt.format, tt.v, ts)
+	}
+}
+
+func TestDecoder_WriteLines(t *testing.T) {
+	d, err := NewDecoder(Schema{Metric: "cpu", Tags: []string{"host"}, Fields: map[string]FieldType{"load": Sum}})
+	assert.NoError(t, err)
+
+	w := mock.NewWrite()
+	var lineErrs []*LineError
+	n, err := d.WriteLines(context.TODO(), w, strings.NewReader(`{"host":"h1","load":1}
+
+{"host":"h2"}
+{"host":"h3","load":3}`), func(err *LineError) {
+		lineErrs = append(lineErrs, err)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Len(t, w.Points(), 2)
+	assert.Equal(t, "h3", w.Points()[1].Tags()["host"])
+	assert.Len(t, lineErrs, 1)
+	assert.Equal(t, 3, lineErrs[0].Line)
+	assert.Equal(t, "line 3: no field found", lineErrs[0].Error())
+	assert.EqualError(t, errors.Unwrap(lineErrs[0]), "no field found")
+
+	// errors ignored
+	n, err = d.WriteLines(context.TODO(), w, strings.NewReader("{}\n{\"load\":1}\n"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// read failure
+	_, err = d.WriteLines(context.TODO(), w, iotest.ErrReader(errors.New("read err")), nil)
+	assert.EqualError(t, err, "read err")
+
+	// context canceled
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	_, err = d.WriteLines(ctx, w, strings.NewReader(`{"load":1}`), nil)
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,101 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jsonpoint
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lindb/client_go/api"
+)
+
+// FieldType represents the LinDB field type which JSON value is mapped to.
+type FieldType = api.FieldType
+
+const (
+	// Sum represents delta sum field.
+	Sum = api.FieldTypeSum
+	// Min represents min field.
+	Min = api.FieldTypeMin
+	// Max represents max field.
+	Max = api.FieldTypeMax
+	// First represents first field.
+	First = api.FieldTypeFirst
+	// Last represents last field.
+	Last = api.FieldTypeLast
+	// CumulativeSum represents cumulative sum field, converted into delta by write client.
+	CumulativeSum = api.FieldTypeCumulativeSum
+)
+
+const (
+	// TimestampUnix represents timestamp in seconds(may be fractional) since epoch.
+	TimestampUnix = "unix"
+	// TimestampUnixMilli represents timestamp in milliseconds since epoch.
+	TimestampUnixMilli = "unix_ms"
+	// TimestampUnixMicro represents timestamp in microseconds since epoch.
+	TimestampUnixMicro = "unix_us"
+	// TimestampUnixNano represents timestamp in nanoseconds since epoch.
+	TimestampUnixNano = "unix_ns"
+)
+
+// Schema represents the mapping of JSON document into point, keys are dot-separated paths of nested objects,
+// e.g. "req.latency".
+type Schema struct {
+	// Metric name of point, or MetricKey used if empty.
+	Metric string `json:"metric" toml:"metric" yaml:"metric"`
+	// Key of metric name in document.
+	MetricKey string `json:"metric-key" toml:"metric-key" yaml:"metric-key"`
+	// Namespace of point, or NamespaceKey used if empty.
+	Namespace string `json:"namespace" toml:"namespace" yaml:"namespace"`
+	// Key of namespace in document, optional.
+	NamespaceKey string `json:"namespace-key" toml:"namespace-key" yaml:"namespace-key"`
+	// Key of timestamp in document, current time used if empty or missing.
+	TimestampKey string `json:"timestamp-key" toml:"timestamp-key" yaml:"timestamp-key"`
+	// Format of timestamp, unix/unix_ms/unix_us/unix_ns for number, Go time layout for string,
+	// default unix_ms for number and RFC3339(nano) for string.
+	TimestampFormat string `json:"timestamp-format" toml:"timestamp-format" yaml:"timestamp-format"`
+	// Keys of tags in document(tag key is the key), value is string/number/bool, missing tag is skipped.
+	Tags []string `json:"tags" toml:"tags" yaml:"tags"`
+	// Keys of fields in document(field name is the key) => field type, value is number or numeric string,
+	// missing field is skipped.
+	Fields map[string]FieldType `json:"fields" toml:"fields" yaml:"fields"`
+}
+
+// Validate checks if schema is valid.
+func (s *Schema) Validate() error {
+	if s.Metric == "" && s.MetricKey == "" {
+		return errors.New("metric or metric key required")
+	}
+	if len(s.Fields) == 0 {
+		return errors.New("fields required")
+	}
+	for key, fieldType := range s.Fields {
+		if key == "" {
+			return errors.New("field key must not be empty")
+		}
+		if !fieldType.Valid() {
+			return fmt.Errorf("unknown type %q of field %q, expect sum/min/max/first/last/cumulative", fieldType, key)
+		}
+	}
+	for _, key := range s.Tags {
+		if key == "" {
+			return errors.New("tag key must not be empty")
+		}
+	}
+	return nil
+}
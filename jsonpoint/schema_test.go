@@ -0,0 +1,46 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jsonpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	assert.NoError(t, (&Schema{Metric: "cpu", Fields: map[string]FieldType{"load": Sum}}).Validate())
+	assert.NoError(t, (&Schema{MetricKey: "name", Fields: map[string]FieldType{"load": CumulativeSum}}).Validate())
+
+	cases := []struct {
+		name   string
+		schema Schema
+	}{
+		{name: "metric required", schema: Schema{Fields: map[string]FieldType{"load": Sum}}},
+		{name: "fields required", schema: Schema{Metric: "cpu"}},
+		{name: "empty field key", schema: Schema{Metric: "cpu", Fields: map[string]FieldType{"": Sum}}},
+		{name: "unknown field type", schema: Schema{Metric: "cpu", Fields: map[string]FieldType{"load": "avg"}}},
+		{name: "empty tag key", schema: Schema{Metric: "cpu", Tags: []string{""}, Fields: map[string]FieldType{"load": Sum}}},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.schema.Validate())
+		})
+	}
+}
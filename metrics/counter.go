@@ -0,0 +1,58 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Counter represents the counter which accumulates delta value within interval, reset after emitted.
+type Counter struct {
+	bits atomic.Uint64 // float64 bits
+}
+
+// Incr increases counter by 1.
+func (c *Counter) Incr() {
+	c.Add(1)
+}
+
+// Add increases counter by delta.
+func (c *Counter) Add(delta float64) {
+	addFloat(&c.bits, delta)
+}
+
+// Get returns the value accumulated within current interval.
+func (c *Counter) Get() float64 {
+	return math.Float64frombits(c.bits.Load())
+}
+
+// snapshot returns the accumulated value, then resets counter.
+func (c *Counter) snapshot() float64 {
+	return math.Float64frombits(c.bits.Swap(0))
+}
+
+// addFloat adds delta into float64 bits atomically.
+func addFloat(bits *atomic.Uint64, delta float64) {
+	for {
+		old := bits.Load()
+		if bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
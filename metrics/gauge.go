@@ -0,0 +1,43 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Gauge represents the gauge which keeps the last value, not reset after emitted.
+type Gauge struct {
+	bits atomic.Uint64 // float64 bits
+}
+
+// Update sets gauge value.
+func (g *Gauge) Update(v float64) {
+	g.bits.Store(math.Float64bits(v))
+}
+
+// Add changes gauge value by delta(negative for decreasing).
+func (g *Gauge) Add(delta float64) {
+	addFloat(&g.bits, delta)
+}
+
+// Get returns current gauge value.
+func (g *Gauge) Get() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
@@ -0,0 +1,71 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/lindb/client_go/api"
+)
+
+var (
+	// DefaultBuckets represents the default bucket upper bounds of histogram.
+	DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	// DefaultTimerBuckets represents the default bucket upper bounds(ms) of timer, LinDB's default bucket layout.
+	DefaultTimerBuckets = api.DefaultHistogramBuckets
+)
+
+// Histogram represents the histogram which records observations into buckets within interval, reset after emitted.
+type Histogram struct {
+	recorder *api.HistogramRecorder
+	timer    bool // whether histogram records duration of timer
+}
+
+// kind returns the instrument kind of histogram.
+func (h *Histogram) kind() string {
+	if h.timer {
+		return "timer"
+	}
+	return "histogram"
+}
+
+// Observe records an observation.
+func (h *Histogram) Observe(v float64) {
+	h.recorder.Observe(v)
+}
+
+// snapshot returns the Histogram field of observations within interval, then resets histogram,
+// returns nil if no observation.
+func (h *Histogram) snapshot() api.Field {
+	return h.recorder.Snapshot()
+}
+
+// Timer represents the timer which records duration(ms) into histogram.
+type Timer struct {
+	histogram *Histogram
+}
+
+// Record records duration.
+func (t *Timer) Record(d time.Duration) {
+	t.histogram.Observe(float64(d) / float64(time.Millisecond))
+}
+
+// RecordSince records duration since start.
+func (t *Timer) RecordSince(start time.Time) {
+	t.Record(time.Since(start))
+}
@@ -0,0 +1,47 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/api"
+)
+
+func TestHistogram(t *testing.T) {
+	h := &Histogram{recorder: api.NewHistogramRecorder(1, 2)}
+	assert.Nil(t, h.snapshot())
+	h.Observe(0.5)
+	h.Observe(3)
+	assert.Equal(t, api.NewHistogram(0.5, 3, 3.5, 2, []float64{1, 0, 1}, []float64{1, 2, math.Inf(1)}), h.snapshot())
+	assert.Nil(t, h.snapshot())
+}
+
+func TestTimer(t *testing.T) {
+	timer := &Timer{histogram: &Histogram{recorder: api.NewHistogramRecorder(10)}}
+	timer.Record(5 * time.Millisecond)
+	timer.Record(20 * time.Millisecond)
+	assert.Equal(t, api.NewHistogram(5, 20, 25, 2, []float64{1, 1}, []float64{10, math.Inf(1)}), timer.histogram.snapshot())
+
+	timer.RecordSince(time.Now())
+	assert.Equal(t, 1.0, timer.histogram.recorder.Count())
+}
@@ -0,0 +1,135 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lindb/client_go/api"
+)
+
+// For testing
+var (
+	nowFn = time.Now
+)
+
+// Registry represents the registry of instruments, instruments are aggregated in memory,
+// then one point per scope(series) is emitted through write client on each interval.
+type Registry struct {
+	write    api.Write
+	interval time.Duration
+
+	scopes map[string]*Scope // series key => scope
+	mutex  sync.RWMutex
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+}
+
+// NewRegistry creates a Registry which emits points through write client every interval,
+// emitting is disabled if interval <= 0, call Flush manually in this case.
+func NewRegistry(write api.Write, interval time.Duration) *Registry {
+	r := &Registry{
+		write:    write,
+		interval: interval,
+		scopes:   make(map[string]*Scope),
+		closed:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if interval > 0 {
+		go r.run()
+	} else {
+		close(r.done)
+	}
+	return r
+}
+
+// NewScope returns the scope of metric with tags(key/value pairs), same scope is returned for same metric/tags,
+// panics if tags are not key/value pairs.
+func (r *Registry) NewScope(metricName string, tags ...string) *Scope {
+	if len(tags)%2 != 0 {
+		panic("lindb: metric tags must be key/value pairs")
+	}
+	tagMap := make(map[string]string, len(tags)/2)
+	for i := 0; i < len(tags); i += 2 {
+		tagMap[tags[i]] = tags[i+1]
+	}
+	key := api.SeriesKey("", metricName, tagMap)
+	r.mutex.RLock()
+	scope, ok := r.scopes[key]
+	r.mutex.RUnlock()
+	if ok {
+		return scope
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if scope, ok = r.scopes[key]; ok {
+		return scope
+	}
+	scope = newScope(metricName, tags)
+	r.scopes[key] = scope
+	return scope
+}
+
+// Flush emits one point per scope through write client immediately.
+func (r *Registry) Flush(ctx context.Context) {
+	r.mutex.RLock()
+	scopes := make([]*Scope, 0, len(r.scopes))
+	for _, scope := range r.scopes {
+		scopes = append(scopes, scope)
+	}
+	r.mutex.RUnlock()
+
+	now := nowFn()
+	for _, scope := range scopes {
+		if point := scope.snapshot(now); point != nil {
+			r.write.AddPoint(ctx, point)
+		}
+	}
+}
+
+// Close stops emitting, then flushes pending data, write client is not closed.
+func (r *Registry) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+		<-r.done
+		r.Flush(context.TODO())
+	})
+}
+
+// run emits points every interval until registry closed.
+func (r *Registry) run() {
+	ticker := time.NewTicker(r.interval)
+	defer func() {
+		ticker.Stop()
+		close(r.done)
+	}()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.Flush(context.TODO())
+		case <-r.closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/internal/mock"
+)
+
+func TestRegistry_NewScope(t *testing.T) {
+	r := NewRegistry(mock.NewWrite(), 0)
+	defer r.Close()
+
+	s1 := r.NewScope("cpu", "host", "h1", "ip", "1.1.1.1")
+	s2 := r.NewScope("cpu", "ip", "1.1.1.1", "host", "h1")
+	assert.Same(t, s1, s2)
+	assert.NotSame(t, s1, r.NewScope("cpu", "host", "h2"))
+	assert.NotSame(t, s1, r.NewScope("memory", "host", "h1", "ip", "1.1.1.1"))
+	assert.Panics(t, func() {
+		r.NewScope("cpu", "host")
+	})
+}
+
+func TestRegistry_Flush(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	nowFn = func() time.Time { return now }
+	defer func() {
+		nowFn = time.Now
+	}()
+
+	w := mock.NewWrite()
+	r := NewRegistry(w, 0)
+	defer r.Close()
+
+	r.NewScope("empty")
+	scope := r.NewScope("http", "path", "/api")
+	scope.NewCounter("requests").Add(3)
+	scope.NewGauge("inflight").Update(2)
+	scope.NewHistogram(1, 10).Observe(5)
+
+	r.Flush(context.TODO())
+	points := w.Points()
+	assert.Len(t, points, 1)
+	p := points[0]
+	assert.Equal(t, "http", p.MetricName())
+	assert.Equal(t, map[string]string{"path": "/api"}, p.Tags())
+	assert.Equal(t, now, p.Timestamp())
+	assert.Len(t, p.Fields(), 3)
+
+	// counter/histogram are reset, gauge is kept
+	r.Flush(context.TODO())
+	points = w.Points()
+	assert.Len(t, points, 2)
+	assert.Len(t, points[1].Fields(), 2)
+}
+
+func TestRegistry_Run(t *testing.T) {
+	w := mock.NewWrite()
+	r := NewRegistry(w, 10*time.Millisecond)
+	r.NewScope("cpu").NewGauge("usage").Update(1)
+
+	assert.Eventually(t, func() bool {
+		return len(w.Points()) > 0
+	}, time.Second, time.Millisecond)
+
+	r.Close()
+	n := len(w.Points())
+	// close twice
+	r.Close()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, n, len(w.Points()))
+}
+
+func TestRegistry_Close(t *testing.T) {
+	w := mock.NewWrite()
+	r := NewRegistry(w, time.Hour)
+	r.NewScope("cpu").NewCounter("load").Incr()
+	// pending data flushed
+	r.Close()
+	assert.Len(t, w.Points(), 1)
+}
@@ -0,0 +1,164 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lindb/client_go/api"
+)
+
+// Scope represents the instruments of a series(metric with tags), each instrument is a field of the metric.
+// Scope has at most one histogram or timer, because histogram is the compound field of point,
+// use separate scopes(metrics) for histogram and timer.
+type Scope struct {
+	metricName string
+	tags       []string
+
+	counters  map[string]*Counter
+	gauges    map[string]*Gauge
+	histogram *Histogram
+	mutex     sync.Mutex
+}
+
+// newScope creates a scope of metric with tags.
+func newScope(metricName string, tags []string) *Scope {
+	return &Scope{
+		metricName: metricName,
+		tags:       append([]string(nil), tags...),
+		counters:   make(map[string]*Counter),
+		gauges:     make(map[string]*Gauge),
+	}
+}
+
+// NewCounter returns the counter of field, same counter is returned for same field, emitted as Sum field.
+func (s *Scope) NewCounter(fieldName string) *Counter {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	counter, ok := s.counters[fieldName]
+	if !ok {
+		counter = &Counter{}
+		s.counters[fieldName] = counter
+	}
+	return counter
+}
+
+// NewGauge returns the gauge of field, same gauge is returned for same field, emitted as Last field.
+func (s *Scope) NewGauge(fieldName string) *Gauge {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	gauge, ok := s.gauges[fieldName]
+	if !ok {
+		gauge = &Gauge{}
+		s.gauges[fieldName] = gauge
+	}
+	return gauge
+}
+
+// NewHistogram returns the histogram of scope with bucket upper bounds(DefaultBuckets if empty),
+// same histogram is returned for same bounds, emitted as Histogram field,
+// panics if bounds are invalid, or scope already has timer or histogram with different bounds.
+func (s *Scope) NewHistogram(bounds ...float64) *Histogram {
+	if len(bounds) == 0 {
+		bounds = DefaultBuckets
+	}
+	return s.newHistogram(false, bounds)
+}
+
+// NewTimer returns the timer of scope which records duration(ms) into histogram(DefaultTimerBuckets),
+// timers of scope share the same histogram, panics if scope already has histogram.
+func (s *Scope) NewTimer() *Timer {
+	return &Timer{histogram: s.newHistogram(true, DefaultTimerBuckets)}
+}
+
+// newHistogram returns the histogram of scope, creates it if not exist,
+// panics if kind(histogram/timer) or bounds conflict with existing histogram.
+func (s *Scope) newHistogram(timer bool, bounds []float64) *Histogram {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	recorder := api.NewHistogramRecorder(bounds...)
+	if s.histogram == nil {
+		s.histogram = &Histogram{recorder: recorder, timer: timer}
+		return s.histogram
+	}
+	switch {
+	case s.histogram.timer != timer:
+		panic(fmt.Sprintf("lindb: metric %s already has %s, histogram and timer cannot share scope",
+			s.metricName, s.histogram.kind()))
+	case !equalBounds(s.histogram.recorder.Bounds(), recorder.Bounds()):
+		panic(fmt.Sprintf("lindb: metric %s already has histogram with bounds %v, got %v",
+			s.metricName, s.histogram.recorder.Bounds(), recorder.Bounds()))
+	}
+	return s.histogram
+}
+
+// snapshot returns the point of scope with aggregated fields, then resets counters/histogram,
+// returns nil if no field.
+func (s *Scope) snapshot(now time.Time) *api.Point {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	point := api.NewPoint(s.metricName).SetTimestamp(now)
+	for i := 0; i < len(s.tags); i += 2 {
+		point.AddTag(s.tags[i], s.tags[i+1])
+	}
+	for _, name := range sortedKeys(s.counters) {
+		point.AddField(api.NewSum(name, s.counters[name].snapshot()))
+	}
+	for _, name := range sortedKeys(s.gauges) {
+		point.AddField(api.NewLast(name, s.gauges[name].Get()))
+	}
+	if s.histogram != nil {
+		if field := s.histogram.snapshot(); field != nil {
+			point.AddField(field)
+		}
+	}
+	if len(point.Fields()) == 0 {
+		return nil
+	}
+	return point
+}
+
+// equalBounds checks if bucket upper bounds are same.
+func equalBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedKeys returns the sorted keys of map.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
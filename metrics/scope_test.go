@@ -0,0 +1,94 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/api"
+)
+
+func TestScope_Instruments(t *testing.T) {
+	s := newScope("cpu", nil)
+	assert.Same(t, s.NewCounter("load"), s.NewCounter("load"))
+	assert.NotSame(t, s.NewCounter("load"), s.NewCounter("idle"))
+	assert.Same(t, s.NewGauge("usage"), s.NewGauge("usage"))
+	h := s.NewHistogram()
+	assert.Equal(t, append(DefaultBuckets, math.Inf(1)), h.recorder.Bounds())
+	assert.Same(t, h, s.NewHistogram())
+	assert.Same(t, h, s.NewHistogram(DefaultBuckets...))
+
+	s = newScope("cpu", nil)
+	timer := s.NewTimer()
+	assert.Equal(t, append(DefaultTimerBuckets, math.Inf(1)), timer.histogram.recorder.Bounds())
+	assert.Same(t, timer.histogram, s.NewTimer().histogram)
+}
+
+func TestScope_HistogramConflict(t *testing.T) {
+	cases := []struct {
+		name   string
+		create func(s *Scope)
+	}{
+		{name: "timer after histogram", create: func(s *Scope) {
+			s.NewHistogram()
+			s.NewTimer()
+		}},
+		{name: "histogram after timer", create: func(s *Scope) {
+			s.NewTimer()
+			s.NewHistogram(DefaultTimerBuckets...)
+		}},
+		{name: "histogram with different bounds", create: func(s *Scope) {
+			s.NewHistogram(1, 2)
+			s.NewHistogram(1, 3)
+		}},
+		{name: "histogram with default bounds", create: func(s *Scope) {
+			s.NewHistogram(1, 2)
+			s.NewHistogram()
+		}},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Panics(t, func() {
+				tt.create(newScope("cpu", nil))
+			})
+		})
+	}
+}
+
+func TestScope_snapshot(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	s := newScope("cpu", []string{"host", "h1"})
+	assert.Nil(t, s.snapshot(now))
+
+	s.NewCounter("b").Add(2)
+	s.NewCounter("a").Incr()
+	s.NewGauge("c").Update(3)
+	s.NewHistogram(1)
+	p := s.snapshot(now)
+	assert.Equal(t, "cpu", p.MetricName())
+	assert.Equal(t, map[string]string{"host": "h1"}, p.Tags())
+	assert.Equal(t, now, p.Timestamp())
+	// fields sorted by name, histogram without observation skipped
+	assert.Equal(t, []api.Field{api.NewSum("a", 1), api.NewSum("b", 2), api.NewLast("c", 3)}, p.Fields())
+	assert.Equal(t, []api.Field{api.NewSum("a", 0), api.NewSum("b", 0), api.NewLast("c", 3)}, s.snapshot(now).Fields())
+}
@@ -19,15 +19,59 @@ package lindb
 
 import (
 	"crypto/tls"
+	"fmt"
+	nethttp "net/http"
+	"net/url"
 
 	"github.com/lindb/client_go/api"
 	"github.com/lindb/client_go/internal/http"
+	"github.com/lindb/client_go/trace"
 )
 
 // Options represents configuration options for client.
 type Options struct {
 	httpOptions  *http.Options     // HTTP options
 	writeOptions *api.WriteOptions // Write options
+
+	// Whether to discover live broker nodes from cluster state api via seed endpoint, default false.
+	discovery bool
+	// Refresh interval(sec) of live broker nodes, default 30.
+	discoveryInterval int64
+	// Default database used if database not specified for write/query, default empty.
+	database string
+}
+
+// SetDatabase sets default database used if database not specified for write/query.
+func (o *Options) SetDatabase(database string) *Options {
+	o.database = database
+	return o
+}
+
+// Database returns default database used if database not specified for write/query.
+func (o *Options) Database() string {
+	return o.database
+}
+
+// SetDiscovery sets whether to discover live broker nodes from cluster state api via seed endpoint.
+func (o *Options) SetDiscovery(discovery bool) *Options {
+	o.discovery = discovery
+	return o
+}
+
+// Discovery returns whether to discover live broker nodes from cluster state api via seed endpoint.
+func (o *Options) Discovery() bool {
+	return o.discovery
+}
+
+// SetDiscoveryInterval sets refresh interval(sec) of live broker nodes.
+func (o *Options) SetDiscoveryInterval(interval int64) *Options {
+	o.discoveryInterval = interval
+	return o
+}
+
+// DiscoveryInterval returns refresh interval(sec) of live broker nodes.
+func (o *Options) DiscoveryInterval() int64 {
+	return o.discoveryInterval
 }
 
 // SetTLSConfig sets TLS configuration for secure connection.
@@ -36,12 +80,140 @@ func (o *Options) SetTLSConfig(tlsConfig *tls.Config) *Options {
 	return o
 }
 
+// SetCAFile sets CA bundle PEM file for verifying broker certificate, file is reloaded when modified(checked every 10s).
+func (o *Options) SetCAFile(caFile string) *Options {
+	o.HTTPOptions().SetCAFile(caFile)
+	return o
+}
+
+// SetClientCertFile sets client certificate/key PEM files for mutual TLS, files are reloaded when modified(checked every 10s).
+func (o *Options) SetClientCertFile(certFile, keyFile string) *Options {
+	o.HTTPOptions().SetClientCertFile(certFile, keyFile)
+	return o
+}
+
+// SetServerName sets server name for verifying broker certificate.
+func (o *Options) SetServerName(serverName string) *Options {
+	o.HTTPOptions().SetServerName(serverName)
+	return o
+}
+
+// SetMinTLSVersion sets minimum TLS version, e.g. tls.VersionTLS12.
+func (o *Options) SetMinTLSVersion(version uint16) *Options {
+	o.HTTPOptions().SetMinTLSVersion(version)
+	return o
+}
+
 // SetReqTimeout sets HTTP request timeout(sec)
 func (o *Options) SetReqTimeout(timeout int64) *Options {
 	o.HTTPOptions().SetReqTimeout(timeout)
 	return o
 }
 
+// SetDialTimeout sets dial timeout(sec) of establishing connection.
+func (o *Options) SetDialTimeout(timeout int64) *Options {
+	o.HTTPOptions().SetDialTimeout(timeout)
+	return o
+}
+
+// SetKeepAlive sets keep-alive period(sec) of active connection.
+func (o *Options) SetKeepAlive(keepAlive int64) *Options {
+	o.HTTPOptions().SetKeepAlive(keepAlive)
+	return o
+}
+
+// SetTLSHandshakeTimeout sets TLS handshake timeout(sec).
+func (o *Options) SetTLSHandshakeTimeout(timeout int64) *Options {
+	o.HTTPOptions().SetTLSHandshakeTimeout(timeout)
+	return o
+}
+
+// SetMaxIdleConns sets maximum number of idle connections across all brokers.
+func (o *Options) SetMaxIdleConns(maxIdleConns int) *Options {
+	o.HTTPOptions().SetMaxIdleConns(maxIdleConns)
+	return o
+}
+
+// SetMaxIdleConnsPerHost sets maximum number of idle connections per broker.
+func (o *Options) SetMaxIdleConnsPerHost(maxIdleConnsPerHost int) *Options {
+	o.HTTPOptions().SetMaxIdleConnsPerHost(maxIdleConnsPerHost)
+	return o
+}
+
+// SetMaxConnsPerHost sets maximum number of connections per broker, 0 means no limit.
+func (o *Options) SetMaxConnsPerHost(maxConnsPerHost int) *Options {
+	o.HTTPOptions().SetMaxConnsPerHost(maxConnsPerHost)
+	return o
+}
+
+// SetIdleConnTimeout sets idle connection timeout(sec).
+func (o *Options) SetIdleConnTimeout(timeout int64) *Options {
+	o.HTTPOptions().SetIdleConnTimeout(timeout)
+	return o
+}
+
+// SetEnableHTTP2 sets whether to attempt HTTP/2 for connection.
+func (o *Options) SetEnableHTTP2(enableHTTP2 bool) *Options {
+	o.HTTPOptions().SetEnableHTTP2(enableHTTP2)
+	return o
+}
+
+// SetProxy sets proxy function for request, e.g. http.ProxyFromEnvironment or http.ProxyURL(url).
+func (o *Options) SetProxy(proxy func(*nethttp.Request) (*url.URL, error)) *Options {
+	o.HTTPOptions().SetProxy(proxy)
+	return o
+}
+
+// SetRoundTripper sets custom round tripper(proxy, instrumentation etc.) used as transport of HTTP client.
+func (o *Options) SetRoundTripper(roundTripper nethttp.RoundTripper) *Options {
+	o.HTTPOptions().SetRoundTripper(roundTripper)
+	return o
+}
+
+// SetHTTPClient sets custom HTTP client used for all write/query requests.
+func (o *Options) SetHTTPClient(httpClient *nethttp.Client) *Options {
+	o.HTTPOptions().SetHTTPClient(httpClient)
+	return o
+}
+
+// SetCredentialsProvider sets credentials provider for authenticating write/query request.
+func (o *Options) SetCredentialsProvider(credentials CredentialsProvider) *Options {
+	o.HTTPOptions().SetCredentialsProvider(credentials)
+	return o
+}
+
+// AddHeader adds static header set for each write/query request.
+func (o *Options) AddHeader(key, value string) *Options {
+	o.HTTPOptions().AddHeader(key, value)
+	return o
+}
+
+// SetWriteHeaderHook sets header hook invoked for each write request(batch).
+func (o *Options) SetWriteHeaderHook(hook HeaderHook) *Options {
+	o.WriteOptions().SetHeaderHook(hook)
+	return o
+}
+
+// SetTracer sets tracer for creating spans around write/query requests,
+// see module github.com/lindb/client_go/trace/otel for OpenTelemetry.
+func (o *Options) SetTracer(tracer trace.Tracer) *Options {
+	o.HTTPOptions().SetTracer(tracer)
+	return o
+}
+
+// SetCircuitBreaker sets consecutive failures of endpoint to open circuit breaker(0 means disabled),
+// and cool-down(s) of open circuit breaker before probing endpoint, shared by write/query requests.
+func (o *Options) SetCircuitBreaker(failureThreshold int, coolDown int64) *Options {
+	o.HTTPOptions().SetCircuitBreaker(failureThreshold, coolDown)
+	return o
+}
+
+// SetCircuitStateHook sets hook invoked when circuit state of endpoint changed.
+func (o *Options) SetCircuitStateHook(hook CircuitStateHook) *Options {
+	o.HTTPOptions().SetCircuitStateHook(hook)
+	return o
+}
+
 // HTTPOptions returns the HTTP options, if not set return default options.
 func (o *Options) HTTPOptions() *http.Options {
 	if o.httpOptions == nil {
@@ -86,6 +258,42 @@ func (o *Options) SetRetryBufferLimit(retryBufferLimit int) *Options {
 	return o
 }
 
+// SetPauseBufferLimit sets maximum number of write request to hold while write client paused.
+func (o *Options) SetPauseBufferLimit(pauseBufferLimit int) *Options {
+	o.WriteOptions().SetPauseBufferLimit(pauseBufferLimit)
+	return o
+}
+
+// SetPointsPerSecond sets maximum number of points sent per second, 0 means no limit.
+func (o *Options) SetPointsPerSecond(pointsPerSecond int) *Options {
+	o.WriteOptions().SetPointsPerSecond(pointsPerSecond)
+	return o
+}
+
+// SetBytesPerSecond sets maximum number of request bytes sent per second, 0 means no limit.
+func (o *Options) SetBytesPerSecond(bytesPerSecond int) *Options {
+	o.WriteOptions().SetBytesPerSecond(bytesPerSecond)
+	return o
+}
+
+// SetRateLimitMode sets behaviour when rate limit is hit(delay/drop).
+func (o *Options) SetRateLimitMode(mode api.RateLimitMode) *Options {
+	o.WriteOptions().SetRateLimitMode(mode)
+	return o
+}
+
+// SetCumulativeExpiry sets expiry(ms) of idle series state for converting cumulative sum into delta.
+func (o *Options) SetCumulativeExpiry(expiry int64) *Options {
+	o.WriteOptions().SetCumulativeExpiry(expiry)
+	return o
+}
+
+// SetAggregateInterval sets time slot(ms) of merging points with same series before encoding, 0 means disabled.
+func (o *Options) SetAggregateInterval(interval int64) *Options {
+	o.WriteOptions().SetAggregateInterval(interval)
+	return o
+}
+
 // WriteOptions returns the write options, if not set return default options.
 func (o *Options) WriteOptions() *api.WriteOptions {
 	if o.writeOptions == nil {
@@ -94,10 +302,25 @@ func (o *Options) WriteOptions() *api.WriteOptions {
 	return o.writeOptions
 }
 
+// Validate checks if options(including http/write options) are valid.
+func (o *Options) Validate() error {
+	if o.discovery && o.discoveryInterval <= 0 {
+		return fmt.Errorf("discovery interval(sec) must be positive, got %d", o.discoveryInterval)
+	}
+	if err := o.HTTPOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid http options: %w", err)
+	}
+	if err := o.WriteOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid write options: %w", err)
+	}
+	return nil
+}
+
 // DefaultOptions creates an Options with default.
 func DefaultOptions() *Options {
 	return &Options{
-		httpOptions:  http.DefaultOptions(),
-		writeOptions: api.DefaultWriteOptions(),
+		httpOptions:       http.DefaultOptions(),
+		writeOptions:      api.DefaultWriteOptions(),
+		discoveryInterval: 30, // 30s
 	}
 }
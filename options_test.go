@@ -19,7 +19,9 @@ package lindb
 
 import (
 	"crypto/tls"
+	nethttp "net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -36,13 +38,81 @@ func TestOptions(t *testing.T) {
 	assert.Equal(t, api.DefaultWriteOptions(), opt.WriteOptions())
 
 	opt.AddDefaultTag("k1", "v1").SetUseGZip(false).SetBatchSize(2_000).
-		SetMaxRetries(10).SetRetryBufferLimit(3_000).
-		SetFlushInterval(1_000).SetReqTimeout(60).SetTLSConfig(&tls.Config{})
+		SetMaxRetries(10).SetRetryBufferLimit(3_000).SetPauseBufferLimit(20).
+		SetPointsPerSecond(100).SetBytesPerSecond(1_000).SetRateLimitMode(api.RateLimitDrop).
+		SetFlushInterval(1_000).SetReqTimeout(60).SetTLSConfig(&tls.Config{}).
+		SetCumulativeExpiry(60_000).SetAggregateInterval(10_000)
 	assert.False(t, opt.WriteOptions().UseGZip())
 	assert.Equal(t, 2_000, opt.WriteOptions().BatchSize())
 	assert.Equal(t, int64(1_000), opt.WriteOptions().FlushInterval())
 	assert.Equal(t, int64(60), opt.HTTPOptions().ReqTimeout())
 	assert.Equal(t, 10, opt.WriteOptions().MaxRetries())
 	assert.Equal(t, 3_000, opt.WriteOptions().RetryBufferLimit())
+	assert.Equal(t, 20, opt.WriteOptions().PauseBufferLimit())
+	assert.Equal(t, 100, opt.WriteOptions().PointsPerSecond())
+	assert.Equal(t, 1_000, opt.WriteOptions().BytesPerSecond())
+	assert.Equal(t, api.RateLimitDrop, opt.WriteOptions().RateLimitMode())
+	assert.Equal(t, int64(60_000), opt.WriteOptions().CumulativeExpiry())
+	assert.Equal(t, int64(10_000), opt.WriteOptions().AggregateInterval())
 	assert.NotNil(t, opt.HTTPOptions().TLSConfig())
+
+	opt.SetCAFile("ca.pem").SetClientCertFile("cert.pem", "key.pem").
+		SetServerName("lindb.io").SetMinTLSVersion(tls.VersionTLS13)
+	assert.Equal(t, "ca.pem", opt.HTTPOptions().CAFile())
+	certFile, keyFile := opt.HTTPOptions().ClientCertFile()
+	assert.Equal(t, "cert.pem", certFile)
+	assert.Equal(t, "key.pem", keyFile)
+	assert.Equal(t, "lindb.io", opt.HTTPOptions().ServerName())
+	assert.Equal(t, uint16(tls.VersionTLS13), opt.HTTPOptions().MinTLSVersion())
+
+	assert.False(t, opt.Discovery())
+	assert.Equal(t, int64(30), opt.DiscoveryInterval())
+	opt.SetDiscovery(true).SetDiscoveryInterval(10)
+	assert.True(t, opt.Discovery())
+	assert.Equal(t, int64(10), opt.DiscoveryInterval())
+
+	assert.Empty(t, opt.Database())
+	opt.SetDatabase("test")
+	assert.Equal(t, "test", opt.Database())
+
+	opt.SetCircuitBreaker(3, 10).SetCircuitStateHook(func(_ string, _, to CircuitState) {
+		assert.Equal(t, CircuitOpen, to)
+	})
+	failureThreshold, coolDown := opt.HTTPOptions().CircuitBreaker()
+	assert.Equal(t, 3, failureThreshold)
+	assert.Equal(t, int64(10), coolDown)
+	assert.NotNil(t, opt.HTTPOptions().CircuitStateHook())
+}
+
+func TestOptions_Validate(t *testing.T) {
+	assert.NoError(t, DefaultOptions().Validate())
+	assert.NoError(t, (&Options{}).Validate())
+
+	assert.ErrorContains(t, DefaultOptions().SetDiscovery(true).SetDiscoveryInterval(0).Validate(), "discovery interval")
+	assert.ErrorContains(t, DefaultOptions().SetReqTimeout(0).Validate(), "invalid http options")
+	assert.ErrorContains(t, DefaultOptions().SetBatchSize(0).Validate(), "invalid write options")
+}
+
+func TestOptions_Transport(t *testing.T) {
+	opt := DefaultOptions().SetDialTimeout(1).SetKeepAlive(2).SetTLSHandshakeTimeout(3).
+		SetMaxIdleConns(10).SetMaxIdleConnsPerHost(5).SetMaxConnsPerHost(8).
+		SetIdleConnTimeout(60).SetEnableHTTP2(true).SetProxy(nethttp.ProxyFromEnvironment)
+	assert.Equal(t, int64(1), opt.HTTPOptions().DialTimeout())
+	assert.Equal(t, int64(2), opt.HTTPOptions().KeepAlive())
+	assert.Equal(t, int64(3), opt.HTTPOptions().TLSHandshakeTimeout())
+	assert.Equal(t, 10, opt.HTTPOptions().MaxIdleConns())
+	assert.Equal(t, 5, opt.HTTPOptions().MaxIdleConnsPerHost())
+	assert.Equal(t, 8, opt.HTTPOptions().MaxConnsPerHost())
+	assert.Equal(t, int64(60), opt.HTTPOptions().IdleConnTimeout())
+	assert.True(t, opt.HTTPOptions().EnableHTTP2())
+	assert.NotNil(t, opt.HTTPOptions().Proxy())
+
+	opt.SetRoundTripper(nethttp.DefaultTransport)
+	assert.Equal(t, nethttp.DefaultTransport, opt.HTTPOptions().RoundTripper())
+	cli := &nethttp.Client{Timeout: time.Minute}
+	opt.SetHTTPClient(cli)
+	assert.Equal(t, time.Minute, opt.HTTPOptions().HTTPClient().Timeout)
+
+	opt.SetCredentialsProvider(NewBearerToken("token"))
+	assert.NotNil(t, opt.HTTPOptions().CredentialsProvider())
 }
@@ -0,0 +1,309 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lindb/client_go/api"
+)
+
+const (
+	// valueField represents the field name of counter/gauge/untyped value.
+	valueField = "value"
+	// sumField represents the field name of summary sum.
+	sumField = "sum"
+	// countField represents the field name of summary count.
+	countField = "count"
+)
+
+// histogramState represents the last cumulative buckets of histogram series.
+type histogramState struct {
+	buckets []float64 // cumulative count of buckets
+	sum     float64
+	count   float64
+}
+
+// Converter represents the converter which converts metric families into points:
+//   - counter: CumulativeSum field "value"(converted into delta by write client)
+//   - gauge/untyped: Last field "value"
+//   - histogram: Histogram field, cumulative buckets are converted into delta of buckets
+//   - summary: Last fields of quantiles(e.g. "p99", "p99_9"), CumulativeSum fields "sum"/"count"
+//
+// Metric name is the name of metric family(sample name for gauge/untyped), labels are mapped to tags.
+// Converter keeps the state of histograms, one converter per scrape target.
+type Converter struct {
+	namespace  string
+	tags       map[string]string
+	histograms map[string]*histogramState // series key => last buckets
+}
+
+// NewConverter creates a Converter.
+func NewConverter() *Converter {
+	return &Converter{
+		histograms: make(map[string]*histogramState),
+	}
+}
+
+// SetNamespace sets namespace of points.
+func (c *Converter) SetNamespace(namespace string) *Converter {
+	c.namespace = namespace
+	return c
+}
+
+// AddTag adds tag for all points(e.g. instance/job).
+func (c *Converter) AddTag(key, value string) *Converter {
+	if c.tags == nil {
+		c.tags = make(map[string]string)
+	}
+	c.tags[key] = value
+	return c
+}
+
+// Convert converts metric families into points, timestamp of sample used if exposed, otherwise now.
+// The first reading of histogram(or after disappeared) is kept as baseline and not converted.
+func (c *Converter) Convert(families []*MetricFamily, now time.Time) []*api.Point {
+	var points []*api.Point
+	histograms := make(map[string]*histogramState, len(c.histograms))
+	for _, family := range families {
+		switch family.Type {
+		case Counter:
+			for i := range family.Samples {
+				sample := &family.Samples[i]
+				if strings.HasSuffix(sample.Name, "_created") || !isFinite(sample.Value) {
+					continue
+				}
+				points = append(points, c.newPoint(family.Name, sample.Labels, sample.Timestamp, now).
+					AddField(api.NewCumulativeSum(valueField, sample.Value)))
+			}
+		case Histogram:
+			points = append(points, c.convertHistogram(family, histograms, now)...)
+		case Summary:
+			points = append(points, c.convertSummary(family, now)...)
+		default:
+			for i := range family.Samples {
+				sample := &family.Samples[i]
+				if !isFinite(sample.Value) {
+					continue
+				}
+				points = append(points, c.newPoint(sample.Name, sample.Labels, sample.Timestamp, now).
+					AddField(api.NewLast(valueField, sample.Value)))
+			}
+		}
+	}
+	// drop state of disappeared histograms
+	c.histograms = histograms
+	return points
+}
+
+// series represents the samples of histogram/summary with same labels(excluding le/quantile).
+type series struct {
+	labels    map[string]string
+	timestamp time.Time
+	bounds    []float64 // le of histogram buckets, quantile of summary
+	values    []float64
+	sum       float64
+	count     float64
+	hasSum    bool
+	hasCount  bool
+}
+
+// groupSeries groups samples by labels excluding label(le/quantile).
+func groupSeries(family *MetricFamily, label string) (map[string]*series, []string) {
+	groups := make(map[string]*series)
+	var keys []string
+	for i := range family.Samples {
+		sample := &family.Samples[i]
+		labels := make(map[string]string, len(sample.Labels))
+		for k, v := range sample.Labels {
+			if k != label {
+				labels[k] = v
+			}
+		}
+		key := api.SeriesKey("", family.Name, labels)
+		s, ok := groups[key]
+		if !ok {
+			s = &series{labels: labels}
+			groups[key] = s
+			keys = append(keys, key)
+		}
+		if !sample.Timestamp.IsZero() {
+			s.timestamp = sample.Timestamp
+		}
+		switch sample.Name {
+		case family.Name + "_sum":
+			s.sum, s.hasSum = sample.Value, true
+		case family.Name + "_count":
+			s.count, s.hasCount = sample.Value, true
+		case family.Name + "_bucket", family.Name:
+			bound, err := parseValue(sample.Labels[label])
+			if err != nil {
+				continue
+			}
+			s.bounds = append(s.bounds, bound)
+			s.values = append(s.values, sample.Value)
+		}
+	}
+	return groups, keys
+}
+
+// convertHistogram converts histogram buckets into delta Histogram field.
+func (c *Converter) convertHistogram(family *MetricFamily, histograms map[string]*histogramState, now time.Time) []*api.Point {
+	groups, keys := groupSeries(family, "le")
+	var points []*api.Point
+	for _, key := range keys {
+		s := groups[key]
+		sort.Sort(bucketsByBound{s})
+		if len(s.bounds) == 0 || !math.IsInf(s.bounds[len(s.bounds)-1], 1) {
+			if !s.hasCount {
+				continue
+			}
+			// +Inf bucket is count
+			s.bounds = append(s.bounds, math.Inf(1))
+			s.values = append(s.values, s.count)
+		}
+		if len(s.bounds) < 2 || s.bounds[0] < 0 || !isFinite(s.sum) {
+			// negative bucket not supported by LinDB
+			continue
+		}
+		count := s.count
+		if !s.hasCount {
+			count = s.values[len(s.values)-1]
+		}
+		current := &histogramState{buckets: s.values, sum: s.sum, count: count}
+		last, ok := c.histograms[key]
+		histograms[key] = current
+		if !ok || len(last.buckets) != len(current.buckets) {
+			// baseline
+			continue
+		}
+		if current.count < last.count {
+			// counter reset, count from 0
+			last = &histogramState{buckets: make([]float64, len(current.buckets))}
+		}
+		values := make([]float64, len(current.buckets))
+		prev := 0.0
+		for i, cumulative := range current.buckets {
+			delta := cumulative - last.buckets[i]
+			values[i] = math.Max(delta-prev, 0)
+			prev = delta
+		}
+		min, max := estimateMinMax(values, s.bounds)
+		points = append(points, c.newPoint(family.Name, s.labels, s.timestamp, now).
+			AddField(api.NewHistogram(min, max, math.Max(current.sum-last.sum, 0), current.count-last.count,
+				values, append([]float64(nil), s.bounds...))))
+	}
+	return points
+}
+
+// estimateMinMax estimates min/max of histogram by bounds of lowest/highest non-empty bucket,
+// min is lower bound of lowest non-empty bucket, max is upper bound of highest non-empty bucket,
+// lower bound is used as max if highest non-empty bucket is +Inf bucket.
+func estimateMinMax(values, bounds []float64) (min, max float64) {
+	lowest, highest := -1, -1
+	for i, v := range values {
+		if v <= 0 {
+			continue
+		}
+		if lowest < 0 {
+			lowest = i
+		}
+		highest = i
+	}
+	if lowest < 0 {
+		// no observations
+		return 0, 0
+	}
+	if lowest > 0 {
+		min = bounds[lowest-1]
+	}
+	max = bounds[highest]
+	if math.IsInf(max, 1) {
+		max = min
+		if highest > 0 {
+			max = bounds[highest-1]
+		}
+	}
+	return min, max
+}
+
+// convertSummary converts summary quantiles into Last fields, sum/count into CumulativeSum fields.
+func (c *Converter) convertSummary(family *MetricFamily, now time.Time) []*api.Point {
+	groups, keys := groupSeries(family, "quantile")
+	var points []*api.Point
+	for _, key := range keys {
+		s := groups[key]
+		point := c.newPoint(family.Name, s.labels, s.timestamp, now)
+		for i, quantile := range s.bounds {
+			if isFinite(s.values[i]) {
+				point.AddField(api.NewLast(quantileField(quantile), s.values[i]))
+			}
+		}
+		if s.hasSum && isFinite(s.sum) {
+			point.AddField(api.NewCumulativeSum(sumField, s.sum))
+		}
+		if s.hasCount && isFinite(s.count) {
+			point.AddField(api.NewCumulativeSum(countField, s.count))
+		}
+		if len(point.Fields()) > 0 {
+			points = append(points, point)
+		}
+	}
+	return points
+}
+
+// newPoint creates point with labels as tags.
+func (c *Converter) newPoint(metricName string, labels map[string]string, timestamp, now time.Time) *api.Point {
+	if timestamp.IsZero() {
+		timestamp = now
+	}
+	point := api.NewPoint(metricName).SetNamespace(c.namespace).SetTimestamp(timestamp)
+	for k, v := range c.tags {
+		point.AddTag(k, v)
+	}
+	for k, v := range labels {
+		point.AddTag(k, v)
+	}
+	return point
+}
+
+// quantileField returns field name of quantile, e.g. 0.99 => p99, 0.999 => p99_9.
+func quantileField(quantile float64) string {
+	return "p" + strings.ReplaceAll(strconv.FormatFloat(quantile*100, 'f', -1, 64), ".", "_")
+}
+
+// isFinite checks if value is not NaN/Inf.
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// bucketsByBound sorts buckets by bound.
+type bucketsByBound struct {
+	*series
+}
+
+func (b bucketsByBound) Len() int           { return len(b.bounds) }
+func (b bucketsByBound) Less(i, j int) bool { return b.bounds[i] < b.bounds[j] }
+func (b bucketsByBound) Swap(i, j int) {
+	b.bounds[i], b.bounds[j] = b.bounds[j], b.bounds[i]
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+}
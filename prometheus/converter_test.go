@@ -0,0 +1,153 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/api"
+)
+
+func convert(t *testing.T, c *Converter, text string, now time.Time) []*api.Point {
+	families, err := Parse(strings.NewReader(text), FormatText)
+	assert.NoError(t, err)
+	return c.Convert(families, now)
+}
+
+func TestConverter_Simple(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := NewConverter().SetNamespace("ns").AddTag("instance", "host1:9100")
+	points := convert(t, c, `# TYPE requests_total counter
+requests_total{code="200"} 10 1699999999000
+requests_total{code="500"} NaN
+# TYPE temperature gauge
+temperature 20.5
+up 1
+`, now)
+	assert.Len(t, points, 3)
+	assert.Equal(t, "requests_total", points[0].MetricName())
+	assert.Equal(t, "ns", points[0].Namespace())
+	assert.Equal(t, map[string]string{"instance": "host1:9100", "code": "200"}, points[0].Tags())
+	assert.Equal(t, time.UnixMilli(1_699_999_999_000), points[0].Timestamp())
+	assert.Equal(t, []api.Field{api.NewCumulativeSum("value", 10)}, points[0].Fields())
+	assert.Equal(t, "temperature", points[1].MetricName())
+	assert.Equal(t, now, points[1].Timestamp())
+	assert.Equal(t, []api.Field{api.NewLast("value", 20.5)}, points[1].Fields())
+	assert.Equal(t, "up", points[2].MetricName())
+}
+
+func TestConverter_Histogram(t *testing.T) {
+	now := time.Now()
+	c := NewConverter()
+	text := func(b1, b2, inf, sum float64) string {
+		return `# TYPE latency histogram
+latency_bucket{path="/",le="1"} ` + format(b1) + `
+latency_bucket{path="/",le="0.5"} ` + format(b2) + `
+latency_bucket{path="/",le="+Inf"} ` + format(inf) + `
+latency_sum{path="/"} ` + format(sum) + `
+latency_count{path="/"} ` + format(inf) + `
+`
+	}
+	bounds := []float64{0.5, 1, math.Inf(1)}
+	// baseline
+	assert.Empty(t, convert(t, c, text(5, 2, 6, 10), now))
+	// delta of buckets
+	points := convert(t, c, text(8, 3, 10, 15), now)
+	assert.Len(t, points, 1)
+	assert.Equal(t, map[string]string{"path": "/"}, points[0].Tags())
+	assert.Equal(t, []api.Field{api.NewHistogram(0, 1, 5, 4, []float64{1, 2, 1}, bounds)}, points[0].Fields())
+	// counter reset
+	points = convert(t, c, text(2, 1, 3, 4), now)
+	assert.Equal(t, []api.Field{api.NewHistogram(0, 1, 4, 3, []float64{1, 1, 1}, bounds)}, points[0].Fields())
+	// disappeared histogram restarts from baseline
+	assert.Len(t, convert(t, c, "up 1\n", now), 1)
+	assert.Empty(t, convert(t, c, text(2, 1, 3, 4), now))
+}
+
+func TestConverter_HistogramWithoutInf(t *testing.T) {
+	c := NewConverter()
+	text := `# TYPE latency histogram
+latency_bucket{le="1"} 1
+latency_sum 1
+latency_count 2
+# TYPE negative histogram
+negative_bucket{le="-1"} 1
+negative_bucket{le="+Inf"} 1
+negative_count 1
+# TYPE no_count histogram
+no_count_bucket{le="1"} 1
+`
+	assert.Empty(t, convert(t, c, text, time.Now()))
+	points := convert(t, c, strings.ReplaceAll(text, "latency_count 2", "latency_count 3"), time.Now())
+	assert.Len(t, points, 1)
+	assert.Equal(t, []api.Field{api.NewHistogram(1, 1, 0, 1, []float64{0, 1}, []float64{1, math.Inf(1)})}, points[0].Fields())
+}
+
+func TestEstimateMinMax(t *testing.T) {
+	bounds := []float64{0.5, 1, 2, math.Inf(1)}
+	cases := []struct {
+		name     string
+		values   []float64
+		min, max float64
+	}{
+		{name: "empty", values: []float64{0, 0, 0, 0}},
+		{name: "first bucket", values: []float64{3, 0, 0, 0}, min: 0, max: 0.5},
+		{name: "middle buckets", values: []float64{0, 1, 2, 0}, min: 0.5, max: 2},
+		{name: "inf bucket", values: []float64{0, 1, 0, 1}, min: 0.5, max: 2},
+		{name: "only inf bucket", values: []float64{0, 0, 0, 1}, min: 2, max: 2},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			min, max := estimateMinMax(tt.values, bounds)
+			assert.Equal(t, tt.min, min)
+			assert.Equal(t, tt.max, max)
+		})
+	}
+}
+
+func TestConverter_Summary(t *testing.T) {
+	c := NewConverter()
+	points := convert(t, c, `# TYPE rpc summary
+rpc{service="a",quantile="0.5"} 0.2
+rpc{service="a",quantile="0.999"} 0.9
+rpc{service="a",quantile="0.99"} NaN
+rpc_sum{service="a"} 10
+rpc_count{service="a"} 50
+rpc{service="b",quantile="0.5"} NaN
+`, time.Now())
+	assert.Len(t, points, 1)
+	assert.Equal(t, "rpc", points[0].MetricName())
+	assert.Equal(t, map[string]string{"service": "a"}, points[0].Tags())
+	assert.Equal(t, []api.Field{
+		api.NewLast("p50", 0.2),
+		api.NewLast("p99_9", 0.9),
+		api.NewCumulativeSum("sum", 10),
+		api.NewCumulativeSum("count", 50),
+	}, points[0].Fields())
+}
+
+func format(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
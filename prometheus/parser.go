@@ -0,0 +1,321 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format represents the text exposition format.
+type Format int
+
+const (
+	// FormatText represents Prometheus text format(timestamp in ms).
+	FormatText Format = iota
+	// FormatOpenMetrics represents OpenMetrics text format(timestamp in seconds, exemplars).
+	FormatOpenMetrics
+)
+
+// MetricType represents the type of metric family.
+type MetricType string
+
+const (
+	// Counter represents counter metric.
+	Counter MetricType = "counter"
+	// Gauge represents gauge metric.
+	Gauge MetricType = "gauge"
+	// Histogram represents histogram metric.
+	Histogram MetricType = "histogram"
+	// Summary represents summary metric.
+	Summary MetricType = "summary"
+	// Untyped represents untyped(unknown) metric.
+	Untyped MetricType = "untyped"
+)
+
+// suffixes represents the sample name suffixes of metric type.
+var suffixes = map[MetricType][]string{
+	Counter:   {"_total", "_created"},
+	Histogram: {"_bucket", "_sum", "_count", "_created"},
+	Summary:   {"_sum", "_count", "_created"},
+}
+
+// Sample represents a sample of metric family.
+type Sample struct {
+	Name      string
+	Labels    map[string]string
+	Value     float64
+	Timestamp time.Time // zero if not exposed
+}
+
+// MetricFamily represents the metric family with samples.
+type MetricFamily struct {
+	Name    string
+	Type    MetricType
+	Help    string
+	Samples []Sample
+}
+
+// LineError represents the error of parsing line.
+type LineError struct {
+	Line int // line number, starts from 1
+	Err  error
+}
+
+// Error returns the error message with line number.
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Parse parses text exposition into metric families(in order of first appearance),
+// returns *LineError if line is invalid.
+func Parse(r io.Reader, format Format) ([]*MetricFamily, error) {
+	p := &parser{format: format, families: make(map[string]*MetricFamily)}
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if lineErr := p.parseLine(strings.TrimSpace(data)); lineErr != nil {
+			return nil, &LineError{Line: line, Err: lineErr}
+		}
+		if err != nil || p.eof {
+			// EOF
+			return p.ordered, nil
+		}
+	}
+}
+
+// parser represents the parser state of text exposition.
+type parser struct {
+	format   Format
+	families map[string]*MetricFamily
+	ordered  []*MetricFamily
+	eof      bool
+}
+
+// parseLine parses comment/sample line.
+func (p *parser) parseLine(line string) error {
+	if line == "" {
+		return nil
+	}
+	if line[0] == '#' {
+		return p.parseComment(line)
+	}
+	sample, err := p.parseSample(line)
+	if err != nil {
+		return err
+	}
+	family := p.familyOf(sample.Name)
+	family.Samples = append(family.Samples, sample)
+	return nil
+}
+
+// parseComment parses HELP/TYPE/EOF comment, other comments are ignored.
+func (p *parser) parseComment(line string) error {
+	fields := strings.SplitN(strings.TrimSpace(line[1:]), " ", 3)
+	switch fields[0] {
+	case "EOF":
+		p.eof = true
+	case "HELP", "TYPE":
+		if len(fields) < 2 || fields[1] == "" {
+			return fmt.Errorf("missing metric name in %s", fields[0])
+		}
+		family := p.family(fields[1])
+		value := ""
+		if len(fields) == 3 {
+			value = fields[2]
+		}
+		if fields[0] == "HELP" {
+			family.Help = value
+			return nil
+		}
+		if len(family.Samples) > 0 {
+			return fmt.Errorf("TYPE of %s after samples", fields[1])
+		}
+		family.Type = MetricType(strings.ToLower(value))
+	}
+	return nil
+}
+
+// family returns metric family by name, creates untyped family if not exist.
+func (p *parser) family(name string) *MetricFamily {
+	family, ok := p.families[name]
+	if !ok {
+		family = &MetricFamily{Name: name, Type: Untyped}
+		p.families[name] = family
+		p.ordered = append(p.ordered, family)
+	}
+	return family
+}
+
+// familyOf returns the metric family which sample belongs to(by name suffix of metric type).
+func (p *parser) familyOf(sampleName string) *MetricFamily {
+	if family, ok := p.families[sampleName]; ok {
+		return family
+	}
+	for metricType, typeSuffixes := range suffixes {
+		for _, suffix := range typeSuffixes {
+			if !strings.HasSuffix(sampleName, suffix) {
+				continue
+			}
+			if family, ok := p.families[strings.TrimSuffix(sampleName, suffix)]; ok && family.Type == metricType {
+				return family
+			}
+		}
+	}
+	return p.family(sampleName)
+}
+
+// parseSample parses sample line: name{label="value",...} value [timestamp] [# exemplar].
+func (p *parser) parseSample(line string) (Sample, error) {
+	sample := Sample{}
+	end := strings.IndexAny(line, "{ ")
+	if end <= 0 {
+		return sample, fmt.Errorf("invalid sample %q", line)
+	}
+	sample.Name = line[:end]
+	rest := line[end:]
+	if rest[0] == '{' {
+		labels, n, err := parseLabels(rest)
+		if err != nil {
+			return sample, err
+		}
+		sample.Labels = labels
+		rest = rest[n:]
+	}
+	if idx := strings.Index(rest, " # "); idx >= 0 && p.format == FormatOpenMetrics {
+		// drop exemplar
+		rest = rest[:idx]
+	}
+	parts := strings.Fields(rest)
+	if len(parts) == 0 || len(parts) > 2 {
+		return sample, fmt.Errorf("invalid value/timestamp of sample %s", sample.Name)
+	}
+	value, err := parseValue(parts[0])
+	if err != nil {
+		return sample, fmt.Errorf("invalid value of sample %s: %w", sample.Name, err)
+	}
+	sample.Value = value
+	if len(parts) == 2 {
+		if sample.Timestamp, err = p.parseTimestamp(parts[1]); err != nil {
+			return sample, fmt.Errorf("invalid timestamp of sample %s: %w", sample.Name, err)
+		}
+	}
+	return sample, nil
+}
+
+// parseTimestamp parses timestamp, ms in text format, seconds in OpenMetrics.
+func (p *parser) parseTimestamp(s string) (time.Time, error) {
+	if p.format == FormatOpenMetrics {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(math.Round(frac*1e3))*int64(time.Millisecond)), nil
+	}
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(ms), nil
+}
+
+// parseLabels parses label set starting with '{', returns labels and length of label set.
+func parseLabels(s string) (map[string]string, int, error) {
+	labels := make(map[string]string)
+	i := 1
+	for {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i >= len(s) {
+			return nil, 0, errors.New("unterminated label set")
+		}
+		if s[i] == '}' {
+			return labels, i + 1, nil
+		}
+		eq := strings.IndexByte(s[i:], '=')
+		if eq <= 0 {
+			return nil, 0, fmt.Errorf("invalid label at %q", s[i:])
+		}
+		name := strings.TrimSpace(s[i : i+eq])
+		i += eq + 1
+		if i >= len(s) || s[i] != '"' {
+			return nil, 0, fmt.Errorf("label value of %s must be quoted", name)
+		}
+		var sb strings.Builder
+		i++
+		closed := false
+		for ; i < len(s); i++ {
+			c := s[i]
+			if c == '"' {
+				closed = true
+				i++
+				break
+			}
+			if c == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					c = '\n'
+				default:
+					c = s[i]
+				}
+			}
+			sb.WriteByte(c)
+		}
+		if !closed {
+			return nil, 0, fmt.Errorf("unterminated label value of %s", name)
+		}
+		labels[name] = sb.String()
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i < len(s) && s[i] == ',' {
+			i++
+		}
+	}
+}
+
+// parseValue parses sample value(including NaN/+Inf/-Inf).
+func parseValue(s string) (float64, error) {
+	switch s {
+	case "+Inf":
+		return math.Inf(1), nil
+	case "-Inf":
+		return math.Inf(-1), nil
+	case "NaN":
+		return math.NaN(), nil
+	default:
+		return strconv.ParseFloat(s, 64)
+	}
+}
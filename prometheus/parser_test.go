@@ -0,0 +1,144 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const textExposition = `# HELP http_requests_total Total requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1700000000123
+http_requests_total{method="post",code="400"}    3
+# TYPE temperature gauge
+temperature{path="C:\\dir",msg="a \"b\"\nc"} -12.5
+# TYPE latency histogram
+latency_bucket{le="0.1"} 2
+latency_bucket{le="+Inf"} 5
+latency_sum 3.5
+latency_count 5
+# TYPE rpc summary
+rpc{quantile="0.5"} 0.2
+rpc_sum 10
+rpc_count 50
+# some comment
+untyped_metric NaN
+`
+
+func TestParse_Text(t *testing.T) {
+	families, err := Parse(strings.NewReader(textExposition), FormatText)
+	assert.NoError(t, err)
+	assert.Len(t, families, 5)
+
+	counter := families[0]
+	assert.Equal(t, "http_requests_total", counter.Name)
+	assert.Equal(t, Counter, counter.Type)
+	assert.Equal(t, "Total requests.", counter.Help)
+	assert.Equal(t, []Sample{
+		{
+			Name:      "http_requests_total",
+			Labels:    map[string]string{"method": "post", "code": "200"},
+			Value:     1027,
+			Timestamp: time.UnixMilli(1_700_000_000_123),
+		},
+		{Name: "http_requests_total", Labels: map[string]string{"method": "post", "code": "400"}, Value: 3},
+	}, counter.Samples)
+
+	gauge := families[1]
+	assert.Equal(t, Gauge, gauge.Type)
+	assert.Equal(t, map[string]string{"path": `C:\dir`, "msg": "a \"b\"\nc"}, gauge.Samples[0].Labels)
+	assert.Equal(t, -12.5, gauge.Samples[0].Value)
+
+	histogram := families[2]
+	assert.Equal(t, Histogram, histogram.Type)
+	assert.Len(t, histogram.Samples, 4)
+	assert.True(t, math.IsInf(mustLabelValue(t, histogram.Samples[1].Labels["le"]), 1))
+
+	summary := families[3]
+	assert.Equal(t, Summary, summary.Type)
+	assert.Len(t, summary.Samples, 3)
+
+	untyped := families[4]
+	assert.Equal(t, Untyped, untyped.Type)
+	assert.True(t, math.IsNaN(untyped.Samples[0].Value))
+}
+
+func mustLabelValue(t *testing.T, s string) float64 {
+	v, err := parseValue(s)
+	assert.NoError(t, err)
+	return v
+}
+
+func TestParse_OpenMetrics(t *testing.T) {
+	families, err := Parse(strings.NewReader(`# TYPE requests counter
+# UNIT requests requests
+requests_total{path="/"} 10 1700000000.5 # {trace_id="abc"} 1 1700000000
+requests_created{path="/"} 1700000000
+# EOF
+ignored 1
+`), FormatOpenMetrics)
+	assert.NoError(t, err)
+	assert.Len(t, families, 1)
+	assert.Equal(t, "requests", families[0].Name)
+	assert.Len(t, families[0].Samples, 2)
+	assert.Equal(t, "requests_total", families[0].Samples[0].Name)
+	assert.Equal(t, 10.0, families[0].Samples[0].Value)
+	assert.Equal(t, time.UnixMilli(1_700_000_000_500), families[0].Samples[0].Timestamp)
+}
+
+func TestParse_Error(t *testing.T) {
+	cases := []struct {
+		text string
+		err  string
+	}{
+		{text: "# TYPE", err: "missing metric name"},
+		{text: "cpu 1\n# TYPE cpu gauge", err: "TYPE of cpu after samples"},
+		{text: "{a=\"b\"} 1", err: "invalid sample"},
+		{text: "cpu", err: "invalid sample"},
+		{text: "cpu{a=\"b\"", err: "unterminated label set"},
+		{text: "cpu{a} 1", err: "invalid label"},
+		{text: "cpu{a=b} 1", err: "must be quoted"},
+		{text: "cpu{a=\"b} 1", err: "unterminated label value"},
+		{text: "cpu{a=\"b\"}", err: "invalid value/timestamp"},
+		{text: "cpu 1 2 3", err: "invalid value/timestamp"},
+		{text: "cpu abc", err: "invalid value of sample cpu"},
+		{text: "cpu 1 abc", err: "invalid timestamp of sample cpu"},
+	}
+	for _, tt := range cases {
+		families, err := Parse(strings.NewReader("\n"+tt.text), FormatText)
+		assert.Nil(t, families, tt.text)
+		assert.ErrorContains(t, err, tt.err, tt.text)
+		var lineErr *LineError
+		assert.True(t, errors.As(err, &lineErr))
+		assert.Greater(t, lineErr.Line, 1)
+	}
+
+	_, err := Parse(strings.NewReader("cpu 1 abc"), FormatOpenMetrics)
+	assert.ErrorContains(t, err, "invalid timestamp")
+
+	_, err = Parse(iotest.ErrReader(io.ErrUnexpectedEOF), FormatText)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
@@ -0,0 +1,281 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/lindb/client_go/api"
+)
+
+const (
+	// metricNameLabel represents the label of metric name.
+	metricNameLabel = "__name__"
+	// defaultMaxBodySize represents the default max size of(compressed/decoded) request body.
+	defaultMaxBodySize = 32 << 20
+)
+
+// counterSuffixes represents the sample name suffixes of cumulative series.
+var counterSuffixes = []string{"_total", "_count", "_sum", "_bucket"}
+
+// RemoteWriteHandler represents the http.Handler receiving Prometheus remote-write(1.0) requests
+// (snappy-compressed protobuf), time series samples are forwarded as points through write client.
+//
+// By default metric name(__name__) is mapped to metric with field "value", other labels are mapped to tags.
+// Samples of counter series(name suffixed with _total/_count/_sum/_bucket) are written as CumulativeSum fields,
+// others as Last fields, stale markers(NaN) are skipped.
+//
+// Status codes:
+//   - 204: samples accepted
+//   - 400: invalid request body
+//   - 405: method not POST
+//   - 413: request body too large
+//   - 415: unsupported content encoding/type
+//   - 429: too many in-flight requests
+//   - 503: write client paused or not accepting points before timeout(no sample accepted), sender should retry later
+//
+// Samples of one request are accepted as a whole, once write client accepts the first point before timeout,
+// rest points are added without timeout, so that retried request does not duplicate accepted samples.
+type RemoteWriteHandler struct {
+	write          api.Write
+	namespace      string
+	namespaceLabel string
+	metricName     string
+	fieldName      string
+	fieldTypes     map[string]api.FieldType
+	timeout        time.Duration
+	maxBodySize    int64
+	inflight       chan struct{}
+}
+
+// NewRemoteWriteHandler creates a RemoteWriteHandler with default timeout(5s)/max body size(32MiB).
+func NewRemoteWriteHandler(write api.Write) *RemoteWriteHandler {
+	return &RemoteWriteHandler{
+		write:       write,
+		fieldName:   valueField,
+		timeout:     5 * time.Second,
+		maxBodySize: defaultMaxBodySize,
+	}
+}
+
+// SetNamespace sets namespace of points.
+func (h *RemoteWriteHandler) SetNamespace(namespace string) *RemoteWriteHandler {
+	h.namespace = namespace
+	return h
+}
+
+// SetNamespaceLabel sets the label whose value is used as namespace(label not written as tag),
+// namespace set by SetNamespace used if label is missing.
+func (h *RemoteWriteHandler) SetNamespaceLabel(label string) *RemoteWriteHandler {
+	h.namespaceLabel = label
+	return h
+}
+
+// SetMetricName sets the metric of all points, then metric name(__name__) of series is used as field name.
+func (h *RemoteWriteHandler) SetMetricName(metricName string) *RemoteWriteHandler {
+	h.metricName = metricName
+	return h
+}
+
+// SetFieldName sets field name of points if metric name not set, default "value".
+func (h *RemoteWriteHandler) SetFieldName(fieldName string) *RemoteWriteHandler {
+	h.fieldName = fieldName
+	return h
+}
+
+// SetFieldType sets field type of series by metric name(__name__), overrides the type picked by suffix.
+func (h *RemoteWriteHandler) SetFieldType(metricName string, fieldType api.FieldType) *RemoteWriteHandler {
+	if h.fieldTypes == nil {
+		h.fieldTypes = make(map[string]api.FieldType)
+	}
+	h.fieldTypes[metricName] = fieldType
+	return h
+}
+
+// SetTimeout sets timeout of waiting write client to accept the first point of one request.
+func (h *RemoteWriteHandler) SetTimeout(timeout time.Duration) *RemoteWriteHandler {
+	h.timeout = timeout
+	return h
+}
+
+// SetMaxBodySize sets max size of(compressed/decoded) request body in bytes.
+func (h *RemoteWriteHandler) SetMaxBodySize(size int64) *RemoteWriteHandler {
+	h.maxBodySize = size
+	return h
+}
+
+// SetMaxInflight sets max number of requests handled concurrently, 0 means unlimited.
+// Must be set before serving requests.
+func (h *RemoteWriteHandler) SetMaxInflight(n int) *RemoteWriteHandler {
+	h.inflight = nil
+	if n > 0 {
+		h.inflight = make(chan struct{}, n)
+	}
+	return h
+}
+
+// ServeHTTP handles remote-write request.
+func (h *RemoteWriteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if encoding := r.Header.Get("Content-Encoding"); encoding != "" && !strings.EqualFold(encoding, "snappy") {
+		http.Error(w, fmt.Sprintf("unsupported content encoding %q", encoding), http.StatusUnsupportedMediaType)
+		return
+	}
+	if contentType := r.Header.Get("Content-Type"); strings.Contains(contentType, "proto=") &&
+		!strings.Contains(contentType, "proto=prometheus.WriteRequest") {
+		// remote-write 2.0 not supported
+		http.Error(w, fmt.Sprintf("unsupported content type %q", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+	if h.inflight != nil {
+		select {
+		case h.inflight <- struct{}{}:
+			defer func() {
+				<-h.inflight
+			}()
+		default:
+			http.Error(w, "too many in-flight requests", http.StatusTooManyRequests)
+			return
+		}
+	}
+	if pauser, ok := h.write.(api.WritePauser); ok && pauser.Paused() {
+		http.Error(w, "write client paused", http.StatusServiceUnavailable)
+		return
+	}
+	series, code, err := h.decode(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	ctx := r.Context()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	var points []*api.Point
+	for i := range series {
+		points = h.appendPoints(points, &series[i])
+	}
+	if len(points) > 0 {
+		// timeout applies to the first point only, request is accepted as a whole
+		h.write.AddPoint(ctx, points[0])
+		if ctx.Err() != nil {
+			http.Error(w, "write client not accepting points: "+ctx.Err().Error(), http.StatusServiceUnavailable)
+			return
+		}
+		for _, point := range points[1:] {
+			h.write.AddPoint(context.Background(), point)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decode reads/decompresses/decodes request body, returns status code if failure.
+func (h *RemoteWriteHandler) decode(w http.ResponseWriter, r *http.Request) ([]timeSeries, int, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, http.StatusRequestEntityTooLarge, err
+		}
+		return nil, http.StatusBadRequest, err
+	}
+	size, err := snappy.DecodedLen(body)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if int64(size) > h.maxBodySize {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("decoded body too large: %d bytes", size)
+	}
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	series, err := decodeWriteRequest(data)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return series, 0, nil
+}
+
+// appendPoints converts samples of time series into points, then appends to points.
+func (h *RemoteWriteHandler) appendPoints(points []*api.Point, ts *timeSeries) []*api.Point {
+	var name string
+	namespace := h.namespace
+	tags := make(map[string]string, len(ts.labels))
+	for _, l := range ts.labels {
+		switch {
+		case l.name == metricNameLabel:
+			name = l.value
+		case h.namespaceLabel != "" && l.name == h.namespaceLabel:
+			namespace = l.value
+		case l.value != "":
+			tags[l.name] = l.value
+		}
+	}
+	if name == "" {
+		return points
+	}
+	metricName, fieldName := name, h.fieldName
+	if h.metricName != "" {
+		metricName, fieldName = h.metricName, name
+	}
+	fieldType := h.fieldType(name)
+	for _, sample := range ts.samples {
+		if math.IsNaN(sample.value) || math.IsInf(sample.value, 0) {
+			// stale marker
+			continue
+		}
+		point := api.NewPoint(metricName).SetNamespace(namespace).
+			SetTimestamp(time.UnixMilli(sample.timestamp)).
+			AddField(fieldType.NewField(fieldName, sample.value))
+		for k, v := range tags {
+			point.AddTag(k, v)
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// fieldType returns field type of series by metric name.
+func (h *RemoteWriteHandler) fieldType(metricName string) api.FieldType {
+	if fieldType, ok := h.fieldTypes[metricName]; ok {
+		return fieldType
+	}
+	for _, suffix := range counterSuffixes {
+		if strings.HasSuffix(metricName, suffix) {
+			return api.FieldTypeCumulativeSum
+		}
+	}
+	return api.FieldTypeLast
+}
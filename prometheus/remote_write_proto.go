@@ -0,0 +1,231 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// wire types of protobuf encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("truncated protobuf message")
+
+// label represents the label of remote-write time series.
+type label struct {
+	name  string
+	value string
+}
+
+// remoteSample represents the sample of remote-write time series.
+type remoteSample struct {
+	value     float64
+	timestamp int64 // ms
+}
+
+// timeSeries represents the time series of remote-write request(exemplars/native histograms skipped).
+type timeSeries struct {
+	labels  []label
+	samples []remoteSample
+}
+
+// decodeWriteRequest decodes prometheus.WriteRequest(remote-write 1.0) message.
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; repeated MetricMetadata metadata = 3; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+func decodeWriteRequest(data []byte) ([]timeSeries, error) {
+	var series []timeSeries
+	err := decodeMessage(data, func(field int, wireType int, b *protoBuffer) error {
+		if field != 1 || wireType != wireBytes {
+			return b.skip(wireType)
+		}
+		msg, err := b.bytes()
+		if err != nil {
+			return err
+		}
+		ts, err := decodeTimeSeries(msg)
+		if err != nil {
+			return err
+		}
+		series = append(series, ts)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// decodeTimeSeries decodes prometheus.TimeSeries message.
+func decodeTimeSeries(data []byte) (ts timeSeries, err error) {
+	err = decodeMessage(data, func(field int, wireType int, b *protoBuffer) error {
+		if (field != 1 && field != 2) || wireType != wireBytes {
+			return b.skip(wireType)
+		}
+		msg, err := b.bytes()
+		if err != nil {
+			return err
+		}
+		if field == 1 {
+			l, err := decodeLabel(msg)
+			if err != nil {
+				return err
+			}
+			ts.labels = append(ts.labels, l)
+			return nil
+		}
+		s, err := decodeSample(msg)
+		if err != nil {
+			return err
+		}
+		ts.samples = append(ts.samples, s)
+		return nil
+	})
+	return ts, err
+}
+
+// decodeLabel decodes prometheus.Label message.
+func decodeLabel(data []byte) (l label, err error) {
+	err = decodeMessage(data, func(field int, wireType int, b *protoBuffer) error {
+		if (field != 1 && field != 2) || wireType != wireBytes {
+			return b.skip(wireType)
+		}
+		v, err := b.bytes()
+		if err != nil {
+			return err
+		}
+		if field == 1 {
+			l.name = string(v)
+		} else {
+			l.value = string(v)
+		}
+		return nil
+	})
+	return l, err
+}
+
+// decodeSample decodes prometheus.Sample message.
+func decodeSample(data []byte) (s remoteSample, err error) {
+	err = decodeMessage(data, func(field int, wireType int, b *protoBuffer) error {
+		switch {
+		case field == 1 && wireType == wireFixed64:
+			v, err := b.fixed64()
+			s.value = math.Float64frombits(v)
+			return err
+		case field == 2 && wireType == wireVarint:
+			v, err := b.varint()
+			s.timestamp = int64(v)
+			return err
+		default:
+			return b.skip(wireType)
+		}
+	})
+	return s, err
+}
+
+// decodeMessage iterates fields of message, fn must consume(or skip) the value of field.
+func decodeMessage(data []byte, fn func(field int, wireType int, b *protoBuffer) error) error {
+	b := &protoBuffer{buf: data}
+	for b.pos < len(b.buf) {
+		key, err := b.varint()
+		if err != nil {
+			return err
+		}
+		field, wireType := int(key>>3), int(key&7)
+		if field <= 0 {
+			return fmt.Errorf("invalid protobuf field number %d", field)
+		}
+		if err := fn(field, wireType, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protoBuffer represents the reader of protobuf encoded message.
+type protoBuffer struct {
+	buf []byte
+	pos int
+}
+
+// varint reads varint value.
+func (b *protoBuffer) varint() (uint64, error) {
+	v, n := binary.Uvarint(b.buf[b.pos:])
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	b.pos += n
+	return v, nil
+}
+
+// fixed64 reads fixed 64-bit value.
+func (b *protoBuffer) fixed64() (uint64, error) {
+	if len(b.buf)-b.pos < 8 {
+		return 0, errTruncated
+	}
+	v := binary.LittleEndian.Uint64(b.buf[b.pos:])
+	b.pos += 8
+	return v, nil
+}
+
+// bytes reads length-delimited value.
+func (b *protoBuffer) bytes() ([]byte, error) {
+	n, err := b.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(b.buf)-b.pos) {
+		return nil, errTruncated
+	}
+	v := b.buf[b.pos : b.pos+int(n)]
+	b.pos += int(n)
+	return v, nil
+}
+
+// skip skips the value of unknown field.
+func (b *protoBuffer) skip(wireType int) error {
+	switch wireType {
+	case wireVarint:
+		_, err := b.varint()
+		return err
+	case wireFixed64:
+		_, err := b.fixed64()
+		return err
+	case wireBytes:
+		_, err := b.bytes()
+		return err
+	case wireFixed32:
+		if len(b.buf)-b.pos < 4 {
+			return errTruncated
+		}
+		b.pos += 4
+		return nil
+	default:
+		return fmt.Errorf("unsupported protobuf wire type %d", wireType)
+	}
+}
@@ -0,0 +1,128 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// protoEncoder encodes protobuf message for testing.
+type protoEncoder struct {
+	buf []byte
+}
+
+func (e *protoEncoder) key(field, wireType int) *protoEncoder {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field<<3|wireType))
+	return e
+}
+
+func (e *protoEncoder) varint(field int, v uint64) *protoEncoder {
+	e.key(field, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, v)
+	return e
+}
+
+func (e *protoEncoder) double(field int, v float64) *protoEncoder {
+	e.key(field, wireFixed64)
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+	return e
+}
+
+func (e *protoEncoder) fixed32(field int, v uint32) *protoEncoder {
+	e.key(field, wireFixed32)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+	return e
+}
+
+func (e *protoEncoder) bytes(field int, v []byte) *protoEncoder {
+	e.key(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(v)))
+	e.buf = append(e.buf, v...)
+	return e
+}
+
+// encodeWriteRequest encodes remote-write request, with unknown fields for checking skip.
+func encodeWriteRequest(series []timeSeries) []byte {
+	req := &protoEncoder{}
+	for _, ts := range series {
+		msg := &protoEncoder{}
+		for _, l := range ts.labels {
+			msg.bytes(1, (&protoEncoder{}).bytes(1, []byte(l.name)).bytes(2, []byte(l.value)).buf)
+		}
+		for _, s := range ts.samples {
+			msg.bytes(2, (&protoEncoder{}).double(1, s.value).varint(2, uint64(s.timestamp)).buf)
+		}
+		// exemplar
+		msg.bytes(3, (&protoEncoder{}).double(2, 1).buf)
+		req.bytes(1, msg.buf)
+	}
+	// metadata
+	req.bytes(3, (&protoEncoder{}).varint(1, 1).bytes(2, []byte("cpu")).fixed32(6, 1).buf)
+	return req.buf
+}
+
+func TestDecodeWriteRequest(t *testing.T) {
+	series := []timeSeries{
+		{
+			labels:  []label{{name: "__name__", value: "cpu"}, {name: "host", value: "h1"}},
+			samples: []remoteSample{{value: 1.5, timestamp: 1_700_000_000_000}, {value: -2, timestamp: 1_700_000_015_000}},
+		},
+		{
+			labels: []label{{name: "__name__", value: "up"}},
+		},
+	}
+	decoded, err := decodeWriteRequest(encodeWriteRequest(series))
+	assert.NoError(t, err)
+	assert.Equal(t, series, decoded)
+
+	decoded, err = decodeWriteRequest(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, decoded)
+}
+
+func TestDecodeWriteRequest_Error(t *testing.T) {
+	label := (&protoEncoder{}).bytes(1, []byte("__name__")).buf
+	sample := (&protoEncoder{}).double(1, 1).varint(2, 1).buf
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated key", data: []byte{0x80}},
+		{name: "field number 0", data: (&protoEncoder{}).varint(0, 1).buf},
+		{name: "truncated bytes", data: (&protoEncoder{}).bytes(1, []byte("abc")).buf[:3]},
+		{name: "unsupported wire type", data: (&protoEncoder{}).key(2, 3).buf},
+		{name: "truncated fixed32", data: (&protoEncoder{}).key(2, wireFixed32).buf},
+		{name: "truncated fixed64", data: (&protoEncoder{}).key(2, wireFixed64).buf},
+		{name: "truncated varint", data: (&protoEncoder{}).key(2, wireVarint).buf},
+		{name: "invalid time series", data: (&protoEncoder{}).bytes(1, []byte{0x80}).buf},
+		{name: "invalid label", data: (&protoEncoder{}).bytes(1, (&protoEncoder{}).bytes(1, label[:len(label)-1]).buf).buf},
+		{name: "invalid sample", data: (&protoEncoder{}).bytes(1, (&protoEncoder{}).bytes(2, sample[:4]).buf).buf},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := decodeWriteRequest(tt.data)
+			assert.Error(t, err)
+			assert.Nil(t, series)
+		})
+	}
+}
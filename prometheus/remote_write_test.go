@@ -0,0 +1,287 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/api"
+	"github.com/lindb/client_go/internal/mock"
+)
+
+type mockRemoteWrite struct {
+	*mock.Write
+	block chan struct{}
+}
+
+func newMockRemoteWrite() *mockRemoteWrite {
+	return &mockRemoteWrite{Write: mock.NewWrite()}
+}
+
+func (w *mockRemoteWrite) AddPoint(ctx context.Context, point *api.Point) {
+	if w.block != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.block:
+		}
+	}
+	w.Write.AddPoint(ctx, point)
+}
+
+func newRemoteWriteRequest(series []timeSeries) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/write",
+		bytes.NewReader(snappy.Encode(nil, encodeWriteRequest(series))))
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	return req
+}
+
+var remoteWriteSeries = []timeSeries{
+	{
+		labels: []label{{name: "__name__", value: "http_requests_total"}, {name: "code", value: "200"},
+			{name: "env", value: ""}, {name: "tenant", value: "t1"}},
+		samples: []remoteSample{{value: 10, timestamp: 1_700_000_000_000}, {value: math.NaN(), timestamp: 1_700_000_015_000}},
+	},
+	{
+		labels:  []label{{name: "__name__", value: "temperature"}, {name: "room", value: "a"}},
+		samples: []remoteSample{{value: 20.5, timestamp: 1_700_000_000_000}},
+	},
+	{
+		labels:  []label{{name: "room", value: "a"}},
+		samples: []remoteSample{{value: 1, timestamp: 1_700_000_000_000}},
+	},
+}
+
+func TestRemoteWriteHandler_ServeHTTP(t *testing.T) {
+	write := newMockRemoteWrite()
+	h := NewRemoteWriteHandler(write).SetNamespace("ns")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRemoteWriteRequest(remoteWriteSeries))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	points := write.Points()
+	assert.Len(t, points, 2)
+	assert.Equal(t, "http_requests_total", points[0].MetricName())
+	assert.Equal(t, "ns", points[0].Namespace())
+	assert.Equal(t, map[string]string{"code": "200", "tenant": "t1"}, points[0].Tags())
+	assert.Equal(t, time.UnixMilli(1_700_000_000_000), points[0].Timestamp())
+	assert.Equal(t, []api.Field{api.NewCumulativeSum("value", 10)}, points[0].Fields())
+	assert.Equal(t, "temperature", points[1].MetricName())
+	assert.Equal(t, []api.Field{api.NewLast("value", 20.5)}, points[1].Fields())
+}
+
+func TestRemoteWriteHandler_Mapping(t *testing.T) {
+	write := newMockRemoteWrite()
+	h := NewRemoteWriteHandler(write).SetNamespace("ns").SetNamespaceLabel("tenant").
+		SetMetricName("prometheus").SetFieldType("temperature", api.FieldTypeMax).
+		SetFieldType("http_requests_total", api.FieldTypeSum)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRemoteWriteRequest(remoteWriteSeries))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	points := write.Points()
+	assert.Len(t, points, 2)
+	assert.Equal(t, "prometheus", points[0].MetricName())
+	assert.Equal(t, "t1", points[0].Namespace())
+	assert.Equal(t, map[string]string{"code": "200"}, points[0].Tags())
+	assert.Equal(t, []api.Field{api.NewSum("http_requests_total", 10)}, points[0].Fields())
+	assert.Equal(t, "ns", points[1].Namespace())
+	assert.Equal(t, []api.Field{api.NewMax("temperature", 20.5)}, points[1].Fields())
+
+	write = newMockRemoteWrite()
+	NewRemoteWriteHandler(write).SetFieldName("v").ServeHTTP(httptest.NewRecorder(), newRemoteWriteRequest(remoteWriteSeries))
+	assert.Equal(t, []api.Field{api.NewLast("v", 20.5)}, write.Points()[1].Fields())
+}
+
+func TestRemoteWriteHandler_FieldType(t *testing.T) {
+	h := NewRemoteWriteHandler(newMockRemoteWrite())
+	assert.Equal(t, api.FieldTypeCumulativeSum, h.fieldType("requests_total"))
+	assert.Equal(t, api.FieldTypeCumulativeSum, h.fieldType("latency_bucket"))
+	assert.Equal(t, api.FieldTypeCumulativeSum, h.fieldType("latency_sum"))
+	assert.Equal(t, api.FieldTypeCumulativeSum, h.fieldType("latency_count"))
+	assert.Equal(t, api.FieldTypeLast, h.fieldType("latency"))
+	assert.Equal(t, api.FieldTypeLast, h.SetFieldType("latency_sum", api.FieldTypeLast).fieldType("latency_sum"))
+}
+
+func TestRemoteWriteHandler_Error(t *testing.T) {
+	snappyRequest := func(data []byte) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader(snappy.Encode(nil, data)))
+	}
+	cases := []struct {
+		name    string
+		handler func(write *mockRemoteWrite) http.Handler
+		req     func() *http.Request
+		code    int
+	}{
+		{
+			name: "method not allowed",
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/api/v1/write", http.NoBody)
+			},
+			code: http.StatusMethodNotAllowed,
+		},
+		{
+			name: "unsupported content encoding",
+			req: func() *http.Request {
+				req := newRemoteWriteRequest(remoteWriteSeries)
+				req.Header.Set("Content-Encoding", "gzip")
+				return req
+			},
+			code: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "remote write 2.0",
+			req: func() *http.Request {
+				req := newRemoteWriteRequest(remoteWriteSeries)
+				req.Header.Set("Content-Type", "application/x-protobuf;proto=io.prometheus.write.v2.Request")
+				return req
+			},
+			code: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "invalid snappy",
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader([]byte{0xff, 0xff}))
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "corrupt snappy",
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader([]byte{0x05, 0x00}))
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "invalid protobuf",
+			req: func() *http.Request {
+				return snappyRequest([]byte{0x80})
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "body too large",
+			handler: func(write *mockRemoteWrite) http.Handler {
+				return NewRemoteWriteHandler(write).SetMaxBodySize(4)
+			},
+			req: func() *http.Request {
+				return newRemoteWriteRequest(remoteWriteSeries)
+			},
+			code: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "decoded body too large",
+			handler: func(write *mockRemoteWrite) http.Handler {
+				return NewRemoteWriteHandler(write).SetMaxBodySize(100)
+			},
+			req: func() *http.Request {
+				return snappyRequest(make([]byte, 1_000))
+			},
+			code: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "paused",
+			handler: func(write *mockRemoteWrite) http.Handler {
+				write.Pause()
+				return NewRemoteWriteHandler(write)
+			},
+			req: func() *http.Request {
+				return newRemoteWriteRequest(remoteWriteSeries)
+			},
+			code: http.StatusServiceUnavailable,
+		},
+		{
+			name: "timeout",
+			handler: func(write *mockRemoteWrite) http.Handler {
+				write.block = make(chan struct{})
+				return NewRemoteWriteHandler(write).SetTimeout(10 * time.Millisecond)
+			},
+			req: func() *http.Request {
+				return newRemoteWriteRequest(remoteWriteSeries)
+			},
+			code: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			write := newMockRemoteWrite()
+			var h http.Handler = NewRemoteWriteHandler(write)
+			if tt.handler != nil {
+				h = tt.handler(write)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, tt.req())
+			assert.Equal(t, tt.code, rec.Code)
+			assert.Empty(t, write.Points())
+		})
+	}
+}
+
+func TestRemoteWriteHandler_AcceptedAsWhole(t *testing.T) {
+	write := newMockRemoteWrite()
+	write.block = make(chan struct{})
+	h := NewRemoteWriteHandler(write).SetTimeout(10 * time.Millisecond)
+	go func() {
+		// accept the first point, then block until timeout passed
+		write.block <- struct{}{}
+		time.Sleep(50 * time.Millisecond)
+		close(write.block)
+	}()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRemoteWriteRequest(remoteWriteSeries))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Len(t, write.Points(), 2)
+}
+
+func TestRemoteWriteHandler_MaxInflight(t *testing.T) {
+	write := newMockRemoteWrite()
+	write.block = make(chan struct{})
+	h := NewRemoteWriteHandler(write).SetMaxInflight(1)
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newRemoteWriteRequest(remoteWriteSeries))
+		done <- rec.Code
+	}()
+	assert.Eventually(t, func() bool {
+		return len(h.inflight) == 1
+	}, time.Second, time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRemoteWriteRequest(remoteWriteSeries))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	close(write.block)
+	assert.Equal(t, http.StatusNoContent, <-done)
+	assert.Len(t, write.Points(), 2)
+	assert.Empty(t, h.inflight)
+
+	assert.Nil(t, h.SetMaxInflight(0).inflight)
+}
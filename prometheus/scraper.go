@@ -0,0 +1,187 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lindb/client_go/api"
+)
+
+// For testing
+var (
+	nowFn = time.Now
+)
+
+// acceptHeader represents the accepted exposition formats, OpenMetrics preferred.
+const acceptHeader = "application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.5,*/*;q=0.1"
+
+// Scraper represents the scraper which periodically fetches metrics of target(e.g. http://exporter:9100/metrics),
+// then writes converted points through write client.
+type Scraper struct {
+	target     string
+	write      api.Write
+	converter  *Converter
+	client     *http.Client
+	interval   time.Duration
+	timeout    time.Duration
+	errHandler func(err error)
+
+	mutex     sync.Mutex // serializes scrapes, converter is not concurrent safe
+	started   atomic.Bool
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+}
+
+// NewScraper creates a Scraper of target with default interval(15s)/timeout(10s).
+func NewScraper(target string, write api.Write) *Scraper {
+	return &Scraper{
+		target:    target,
+		write:     write,
+		converter: NewConverter(),
+		client:    http.DefaultClient,
+		interval:  15 * time.Second,
+		timeout:   10 * time.Second,
+		closed:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// SetInterval sets scrape interval.
+func (s *Scraper) SetInterval(interval time.Duration) *Scraper {
+	s.interval = interval
+	return s
+}
+
+// SetTimeout sets timeout of each scrape.
+func (s *Scraper) SetTimeout(timeout time.Duration) *Scraper {
+	s.timeout = timeout
+	return s
+}
+
+// SetHTTPClient sets HTTP client used for fetching metrics.
+func (s *Scraper) SetHTTPClient(client *http.Client) *Scraper {
+	s.client = client
+	return s
+}
+
+// SetErrorHandler sets handler invoked when scrape failed in background.
+func (s *Scraper) SetErrorHandler(handler func(err error)) *Scraper {
+	s.errHandler = handler
+	return s
+}
+
+// Converter returns the converter of scraper, e.g. setting namespace/tags.
+func (s *Scraper) Converter() *Converter {
+	return s.converter
+}
+
+// Start starts scraping every interval in background until closed, only the first call takes effect.
+func (s *Scraper) Start() {
+	if s.started.CompareAndSwap(false, true) {
+		go s.run()
+	}
+}
+
+// Close stops scraping(waits in-flight scrape completed), write client is not closed.
+func (s *Scraper) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		if s.started.Load() {
+			<-s.done
+		}
+	})
+}
+
+// Scrape fetches metrics of target once, then writes converted points through write client.
+func (s *Scraper) Scrape(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.target, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", acceptHeader)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("scrape %s: unexpected status %s", s.target, resp.Status)
+	}
+	format := FormatText
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/openmetrics-text") {
+		format = FormatOpenMetrics
+	}
+	families, err := Parse(resp.Body, format)
+	if err != nil {
+		return fmt.Errorf("scrape %s: %w", s.target, err)
+	}
+	for _, point := range s.converter.Convert(families, nowFn()) {
+		s.write.AddPoint(ctx, point)
+	}
+	return ctx.Err()
+}
+
+// run scrapes every interval until closed.
+func (s *Scraper) run() {
+	ticker := time.NewTicker(s.interval)
+	defer func() {
+		ticker.Stop()
+		close(s.done)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		if err := s.Scrape(ctx); err != nil && s.errHandler != nil && ctx.Err() == nil {
+			s.errHandler(err)
+		}
+		select {
+		case <-ticker.C:
+		case <-s.closed:
+			return
+		}
+	}
+}
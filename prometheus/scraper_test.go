@@ -0,0 +1,138 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/internal/mock"
+)
+
+func TestScraper_Scrape(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	nowFn = func() time.Time { return now }
+	defer func() {
+		nowFn = time.Now
+	}()
+
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		timestamp   time.Time
+	}{
+		{
+			name:        "text",
+			contentType: "text/plain; version=0.0.4",
+			body:        "# TYPE up gauge\nup 1 1699999999000\n",
+			timestamp:   time.UnixMilli(1_699_999_999_000),
+		},
+		{
+			name:        "open metrics",
+			contentType: "application/openmetrics-text; version=1.0.0; charset=utf-8",
+			body:        "# TYPE up gauge\nup 1 1699999999\n# EOF\n",
+			timestamp:   time.Unix(1_699_999_999, 0),
+		},
+		{
+			name:        "without timestamp",
+			contentType: "text/plain",
+			body:        "up 1\n",
+			timestamp:   now,
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, acceptHeader, r.Header.Get("Accept"))
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			write := mock.NewWrite()
+			s := NewScraper(server.URL, write)
+			s.Converter().AddTag("instance", "exporter")
+			assert.NoError(t, s.Scrape(context.TODO()))
+			points := write.Points()
+			assert.Len(t, points, 1)
+			assert.Equal(t, "up", points[0].MetricName())
+			assert.Equal(t, map[string]string{"instance": "exporter"}, points[0].Tags())
+			assert.Equal(t, tt.timestamp, points[0].Timestamp())
+		})
+	}
+}
+
+func TestScraper_Scrape_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/invalid":
+			_, _ = w.Write([]byte("up{ 1\n"))
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	write := mock.NewWrite()
+	assert.ErrorContains(t, NewScraper(server.URL, write).Scrape(context.TODO()), "unexpected status 500")
+	assert.ErrorContains(t, NewScraper(server.URL+"/invalid", write).Scrape(context.TODO()), "line 1")
+	assert.Error(t, NewScraper(server.URL+"/slow", write).SetTimeout(10*time.Millisecond).Scrape(context.TODO()))
+	assert.Error(t, NewScraper(":/invalid-url", write).Scrape(context.TODO()))
+	assert.Empty(t, write.Points())
+}
+
+func TestScraper_Start(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("up 1\n"))
+	}))
+	defer server.Close()
+
+	write := mock.NewWrite()
+	var errs atomic.Int64
+	s := NewScraper(server.URL, write).SetInterval(10 * time.Millisecond).
+		SetHTTPClient(server.Client()).
+		SetErrorHandler(func(err error) {
+			errs.Add(1)
+		})
+	s.Start()
+	s.Start()
+	assert.Eventually(t, func() bool {
+		return len(write.Points()) >= 2
+	}, time.Second, 5*time.Millisecond)
+	s.Close()
+	s.Close()
+	assert.Equal(t, int64(1), errs.Load())
+
+	// close without start
+	NewScraper(server.URL, write).Close()
+}
@@ -0,0 +1,167 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lindb/client_go/api"
+)
+
+const (
+	// countField represents the field name of counter.
+	countField = "count"
+	// valueField represents the field name of gauge.
+	valueField = "value"
+	// uniqueField represents the field name of set(number of unique values).
+	uniqueField = "unique"
+)
+
+// series represents the aggregated metrics of name/tags within flush interval.
+type series struct {
+	name      string
+	tags      map[string]string
+	updated   bool // updated since last flush
+	count     float64
+	hasCount  bool
+	gauge     float64
+	hasGauge  bool
+	gaugeAt   time.Time // flush time of last gauge update, zero if gauge never updated
+	set       map[string]struct{}
+	histogram *api.HistogramRecorder
+}
+
+// aggregator represents the aggregator of metrics within flush interval, concurrent safe.
+//   - counter: Sum field "count"(value divided by sample rate)
+//   - gauge: Last field "value", delta applied to the value of last interval
+//   - set: Last field "unique"
+//   - timer/histogram/distribution: Histogram field(weighted by 1/sample rate, negative values dropped)
+//
+// Series not updated within interval are not written, gauge value of idle series is kept until expiry
+// so that delta arrived after idle intervals applies to the last value, other idle series are dropped.
+type aggregator struct {
+	bounds      []float64
+	gaugeExpiry time.Duration
+	series      map[string]*series // series key => series
+	mutex       sync.Mutex
+}
+
+// newAggregator creates an aggregator with histogram bucket upper bounds and expiry of idle gauge value.
+func newAggregator(bounds []float64, gaugeExpiry time.Duration) *aggregator {
+	return &aggregator{
+		bounds:      bounds,
+		gaugeExpiry: gaugeExpiry,
+		series:      make(map[string]*series),
+	}
+}
+
+// add aggregates metric.
+func (a *aggregator) add(m *Metric) {
+	switch m.Type {
+	case Timer, Histogram, Distribution:
+		if m.Value < 0 {
+			// negative bucket not supported by LinDB
+			return
+		}
+	}
+	key := api.SeriesKey("", m.Name, m.Tags)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	s, ok := a.series[key]
+	if !ok {
+		s = &series{name: m.Name, tags: m.Tags}
+		a.series[key] = s
+	}
+	s.updated = true
+	switch m.Type {
+	case Counter:
+		s.count += m.Value / m.SampleRate
+		s.hasCount = true
+	case Gauge:
+		if m.Delta {
+			s.gauge += m.Value
+		} else {
+			s.gauge = m.Value
+		}
+		s.hasGauge = true
+	case Set:
+		if s.set == nil {
+			s.set = make(map[string]struct{})
+		}
+		s.set[m.SetValue] = struct{}{}
+	default:
+		if s.histogram == nil {
+			s.histogram = api.NewHistogramRecorder(a.bounds...)
+		}
+		// sampled observation represents 1/sample rate observations
+		s.histogram.ObserveN(m.Value, 1/m.SampleRate)
+	}
+}
+
+// flush returns one point per series updated within interval, then resets aggregated metrics,
+// drops idle series without gauge value or with gauge value expired.
+func (a *aggregator) flush(now time.Time) []*api.Point {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var points []*api.Point
+	for key, s := range a.series {
+		if !s.updated {
+			if s.gaugeAt.IsZero() || now.Sub(s.gaugeAt) >= a.gaugeExpiry {
+				delete(a.series, key)
+			}
+			continue
+		}
+		if point := s.snapshot(now); point != nil {
+			points = append(points, point)
+		}
+	}
+	return points
+}
+
+// snapshot returns the point of aggregated metrics, then resets series(gauge value kept for delta),
+// returns nil if nothing aggregated.
+func (s *series) snapshot(now time.Time) *api.Point {
+	point := api.NewPoint(s.name).SetTimestamp(now)
+	for k, v := range s.tags {
+		point.AddTag(k, v)
+	}
+	if s.hasCount {
+		point.AddField(api.NewSum(countField, s.count))
+	}
+	if s.hasGauge {
+		point.AddField(api.NewLast(valueField, s.gauge))
+		s.gaugeAt = now
+	}
+	if len(s.set) > 0 {
+		point.AddField(api.NewLast(uniqueField, float64(len(s.set))))
+	}
+	if s.histogram != nil {
+		if field := s.histogram.Snapshot(); field != nil {
+			point.AddField(field)
+		}
+	}
+	s.updated, s.count, s.hasCount, s.hasGauge, s.set = false, 0, false, false, nil
+	if len(point.Fields()) == 0 {
+		return nil
+	}
+	return point
+}
@@ -0,0 +1,94 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/api"
+)
+
+func TestAggregator(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	a := newAggregator([]float64{10, 100}, time.Minute)
+	add := func(lines string) {
+		assert.NoError(t, Parse([]byte(lines), a.add))
+	}
+	flush := func() []*api.Point {
+		now = now.Add(10 * time.Second)
+		points := a.flush(now)
+		sort.Slice(points, func(i, j int) bool {
+			return api.SeriesKey("", points[i].MetricName(), points[i].Tags()) < api.SeriesKey("", points[j].MetricName(), points[j].Tags())
+		})
+		return points
+	}
+
+	add("requests:1|c\nrequests:2|c|@0.5\nrequests:1|c|#env:prod\n" +
+		"temperature:20|g\ntemperature:+2|g\nusers:a|s\nusers:b|s\nusers:a|s\n" +
+		"latency:5|ms\nlatency:50:500|d\nlatency:-1|h")
+	points := flush()
+	assert.Len(t, points, 5)
+	assert.Equal(t, "latency", points[0].MetricName())
+	assert.Equal(t, []api.Field{api.NewHistogram(5, 500, 555, 3, []float64{1, 1, 1},
+		[]float64{10, 100, math.Inf(1)})}, points[0].Fields())
+	assert.Equal(t, now, points[0].Timestamp())
+	assert.Equal(t, "requests", points[1].MetricName())
+	assert.Empty(t, points[1].Tags())
+	assert.Equal(t, []api.Field{api.NewSum(countField, 5)}, points[1].Fields())
+	assert.Equal(t, map[string]string{"env": "prod"}, points[2].Tags())
+	assert.Equal(t, []api.Field{api.NewSum(countField, 1)}, points[2].Fields())
+	assert.Equal(t, []api.Field{api.NewLast(valueField, 22)}, points[3].Fields())
+	assert.Equal(t, []api.Field{api.NewLast(uniqueField, 2)}, points[4].Fields())
+
+	// gauge delta applied to last value
+	add("temperature:-5|g\nrequests:1|c\nrequests:1|g")
+	points = flush()
+	assert.Len(t, points, 2)
+	assert.Equal(t, []api.Field{api.NewSum(countField, 1), api.NewLast(valueField, 1)}, points[0].Fields())
+	assert.Equal(t, []api.Field{api.NewLast(valueField, 17)}, points[1].Fields())
+
+	// idle series not written, gauge value kept across idle intervals
+	assert.Empty(t, flush())
+	assert.Empty(t, flush())
+	assert.Len(t, a.series, 2)
+	add("temperature:+1|g")
+	points = flush()
+	assert.Len(t, points, 1)
+	assert.Equal(t, []api.Field{api.NewLast(valueField, 18)}, points[0].Fields())
+
+	// idle gauge value expired
+	now = now.Add(time.Minute)
+	assert.Empty(t, flush())
+	assert.Empty(t, a.series)
+	add("temperature:+1|g")
+	assert.Equal(t, []api.Field{api.NewLast(valueField, 1)}, flush()[0].Fields())
+
+	// negative observation only
+	add("latency:-1|ms")
+	assert.Empty(t, flush())
+
+	// sampled timer scaled by 1/sample rate
+	add("latency:5|ms|@0.1\nlatency:50|ms|@0.5")
+	assert.Equal(t, []api.Field{api.NewHistogram(5, 50, 150, 12, []float64{10, 2, 0},
+		[]float64{10, 100, math.Inf(1)})}, flush()[0].Fields())
+}
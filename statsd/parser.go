@@ -0,0 +1,176 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MetricType represents the type of StatsD metric.
+type MetricType string
+
+const (
+	// Counter represents counter metric, e.g. requests:1|c.
+	Counter MetricType = "c"
+	// Gauge represents gauge metric, e.g. temperature:20|g, signed value is delta, e.g. temperature:-1|g.
+	Gauge MetricType = "g"
+	// Timer represents timer metric(ms), e.g. latency:320|ms.
+	Timer MetricType = "ms"
+	// Histogram represents histogram metric, e.g. size:512|h.
+	Histogram MetricType = "h"
+	// Distribution represents DogStatsD distribution metric, e.g. latency:320|d.
+	Distribution MetricType = "d"
+	// Set represents set metric which counts unique values, e.g. users:alice|s.
+	Set MetricType = "s"
+)
+
+// Metric represents a StatsD metric.
+type Metric struct {
+	Name       string
+	Type       MetricType
+	Value      float64
+	SetValue   string            // value of set metric
+	Delta      bool              // signed value of gauge
+	SampleRate float64           // (0,1], 1 if not set
+	Tags       map[string]string // DogStatsD tags, tags without value are dropped
+}
+
+// Parse parses newline separated lines of packet, fn is invoked for each metric, invalid lines are skipped,
+// DogStatsD events/service checks are ignored, returns error of first invalid line.
+func Parse(packet []byte, fn func(m *Metric)) error {
+	var firstErr error
+	for len(packet) > 0 {
+		var line []byte
+		if idx := bytes.IndexByte(packet, '\n'); idx >= 0 {
+			line, packet = packet[:idx], packet[idx+1:]
+		} else {
+			line, packet = packet, nil
+		}
+		if err := ParseLine(string(bytes.TrimSpace(line)), fn); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ParseLine parses single line, fn is invoked for each value(DogStatsD multiple values, e.g. latency:1:2|d),
+// nothing is invoked if line is blank, event or service check.
+//
+//	<name>:<value>[:<value>...]|<type>[|@<sample rate>][|#<tag>:<value>,...]
+func ParseLine(line string, fn func(m *Metric)) error {
+	if line == "" || strings.HasPrefix(line, "_e{") || strings.HasPrefix(line, "_sc|") {
+		return nil
+	}
+	metric, err := parseLine(line)
+	if err != nil {
+		return fmt.Errorf("invalid line %q: %w", line, err)
+	}
+	values := strings.Split(metric.SetValue, ":")
+	if metric.Type == Set {
+		values = []string{metric.SetValue}
+	}
+	metrics := make([]Metric, 0, len(values))
+	for _, value := range values {
+		m := *metric
+		if m.Type != Set {
+			m.SetValue = ""
+			if m.Value, err = parseValue(value); err != nil {
+				return fmt.Errorf("invalid line %q: %w", line, err)
+			}
+			m.Delta = m.Type == Gauge && (value[0] == '+' || value[0] == '-')
+		}
+		metrics = append(metrics, m)
+	}
+	for i := range metrics {
+		fn(&metrics[i])
+	}
+	return nil
+}
+
+// parseLine parses line into metric with raw value kept in SetValue.
+func parseLine(line string) (*Metric, error) {
+	sections := strings.Split(line, "|")
+	if len(sections) < 2 {
+		return nil, errors.New("missing metric type")
+	}
+	idx := strings.IndexByte(sections[0], ':')
+	if idx <= 0 {
+		return nil, errors.New("missing metric name or value")
+	}
+	m := &Metric{
+		Name:       sections[0][:idx],
+		Type:       MetricType(sections[1]),
+		SetValue:   sections[0][idx+1:],
+		SampleRate: 1,
+	}
+	if m.SetValue == "" {
+		return nil, errors.New("missing metric value")
+	}
+	switch m.Type {
+	case Counter, Gauge, Timer, Histogram, Distribution, Set:
+	default:
+		return nil, fmt.Errorf("unknown metric type %q", sections[1])
+	}
+	for _, section := range sections[2:] {
+		switch {
+		case strings.HasPrefix(section, "@"):
+			rate, err := strconv.ParseFloat(section[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return nil, fmt.Errorf("invalid sample rate %q", section[1:])
+			}
+			m.SampleRate = rate
+		case strings.HasPrefix(section, "#"):
+			m.Tags = parseTags(section[1:], m.Tags)
+		default:
+			// container id(c:), timestamp(T) etc. ignored
+		}
+	}
+	return m, nil
+}
+
+// parseTags parses DogStatsD tags, e.g. env:prod,region:us, tags without value are dropped.
+func parseTags(s string, tags map[string]string) map[string]string {
+	for _, tag := range strings.Split(s, ",") {
+		idx := strings.IndexByte(tag, ':')
+		if idx <= 0 || idx == len(tag)-1 {
+			continue
+		}
+		if tags == nil {
+			tags = make(map[string]string)
+		}
+		tags[tag[:idx]] = tag[idx+1:]
+	}
+	return tags
+}
+
+// parseValue parses finite value.
+func parseValue(s string) (float64, error) {
+	if s == "" {
+		return 0, errors.New("missing metric value")
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid metric value %q", s)
+	}
+	return v, nil
+}
@@ -0,0 +1,129 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLine(t *testing.T) {
+	cases := []struct {
+		line    string
+		metrics []Metric
+	}{
+		{line: "", metrics: nil},
+		{line: "_e{5,4}:title|text|#env:prod", metrics: nil},
+		{line: "_sc|redis|0", metrics: nil},
+		{
+			line:    "requests:1|c",
+			metrics: []Metric{{Name: "requests", Type: Counter, Value: 1, SampleRate: 1}},
+		},
+		{
+			line: "requests:2|c|@0.1|#env:prod,region:us,flag,:x,y:",
+			metrics: []Metric{{Name: "requests", Type: Counter, Value: 2, SampleRate: 0.1,
+				Tags: map[string]string{"env": "prod", "region": "us"}}},
+		},
+		{
+			line:    "temperature:20.5|g",
+			metrics: []Metric{{Name: "temperature", Type: Gauge, Value: 20.5, SampleRate: 1}},
+		},
+		{
+			line: "temperature:+1|g|#room:a|c:container|T1700000000",
+			metrics: []Metric{{Name: "temperature", Type: Gauge, Value: 1, Delta: true, SampleRate: 1,
+				Tags: map[string]string{"room": "a"}}},
+		},
+		{
+			line:    "temperature:-1.5|g",
+			metrics: []Metric{{Name: "temperature", Type: Gauge, Value: -1.5, Delta: true, SampleRate: 1}},
+		},
+		{
+			line:    "requests:-1|c",
+			metrics: []Metric{{Name: "requests", Type: Counter, Value: -1, SampleRate: 1}},
+		},
+		{
+			line:    "latency:320|ms",
+			metrics: []Metric{{Name: "latency", Type: Timer, Value: 320, SampleRate: 1}},
+		},
+		{
+			line: "latency:1:2.5|d",
+			metrics: []Metric{
+				{Name: "latency", Type: Distribution, Value: 1, SampleRate: 1},
+				{Name: "latency", Type: Distribution, Value: 2.5, SampleRate: 1},
+			},
+		},
+		{
+			line:    "size:512|h",
+			metrics: []Metric{{Name: "size", Type: Histogram, Value: 512, SampleRate: 1}},
+		},
+		{
+			line:    "users:alice:1|s",
+			metrics: []Metric{{Name: "users", Type: Set, SetValue: "alice:1", SampleRate: 1}},
+		},
+	}
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.line, func(t *testing.T) {
+			var metrics []Metric
+			assert.NoError(t, ParseLine(tt.line, func(m *Metric) {
+				metrics = append(metrics, *m)
+			}))
+			assert.Equal(t, tt.metrics, metrics)
+		})
+	}
+}
+
+func TestParseLine_Error(t *testing.T) {
+	cases := []struct {
+		line string
+		err  string
+	}{
+		{line: "requests", err: "missing metric type"},
+		{line: "requests|c", err: "missing metric name or value"},
+		{line: ":1|c", err: "missing metric name or value"},
+		{line: "requests:|c", err: "missing metric value"},
+		{line: "requests:1:|c", err: "missing metric value"},
+		{line: "requests:1|x", err: "unknown metric type"},
+		{line: "requests:abc|c", err: "invalid metric value"},
+		{line: "requests:NaN|c", err: "invalid metric value"},
+		{line: "requests:1:abc|c", err: "invalid metric value"},
+		{line: "requests:1|c|@abc", err: "invalid sample rate"},
+		{line: "requests:1|c|@0", err: "invalid sample rate"},
+		{line: "requests:1|c|@2", err: "invalid sample rate"},
+	}
+	for _, tt := range cases {
+		called := false
+		err := ParseLine(tt.line, func(_ *Metric) {
+			called = true
+		})
+		assert.ErrorContains(t, err, tt.err, tt.line)
+		assert.False(t, called, tt.line)
+	}
+}
+
+func TestParse(t *testing.T) {
+	var names []string
+	err := Parse([]byte("a:1|c\n\nb:1|x\nc:2|g\r\nd:abc|c\ne:1|ms"), func(m *Metric) {
+		names = append(names, m.Name)
+	})
+	assert.ErrorContains(t, err, `invalid line "b:1|x"`)
+	assert.Equal(t, []string{"a", "c", "e"}, names)
+
+	assert.NoError(t, Parse(nil, func(_ *Metric) {}))
+}
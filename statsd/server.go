@@ -0,0 +1,331 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/lindb/client_go/api"
+)
+
+// For testing
+var (
+	nowFn = time.Now
+)
+
+// maxPacketSize represents the max size of UDP packet/TCP line.
+const maxPacketSize = 64 * 1024
+
+// Server represents the StatsD server which listens on UDP(and optional TCP), aggregates metrics,
+// then writes one point per series(name/tags) through write client every flush interval.
+type Server struct {
+	write         api.Write
+	addr          string
+	tcpAddr       string
+	namespace     string
+	tags          map[string]string
+	flushInterval time.Duration
+	gaugeExpiry   time.Duration
+	bounds        []float64
+	errHandler    func(err error)
+
+	aggregator *aggregator
+	udpConn    net.PacketConn
+	listener   net.Listener
+	conns      map[net.Conn]struct{}
+	mutex      sync.Mutex
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewServer creates a Server listening on UDP :8125, flushes every 10s,
+// timers are recorded by LinDB's default bucket layout.
+func NewServer(write api.Write) *Server {
+	return &Server{
+		write:         write,
+		addr:          ":8125",
+		flushInterval: 10 * time.Second,
+		gaugeExpiry:   5 * time.Minute,
+		bounds:        api.DefaultHistogramBuckets,
+		conns:         make(map[net.Conn]struct{}),
+		closed:        make(chan struct{}),
+	}
+}
+
+// SetAddr sets UDP listen address, UDP disabled if empty.
+func (s *Server) SetAddr(addr string) *Server {
+	s.addr = addr
+	return s
+}
+
+// SetTCPAddr sets TCP listen address(newline separated lines), TCP disabled if empty(default).
+func (s *Server) SetTCPAddr(addr string) *Server {
+	s.tcpAddr = addr
+	return s
+}
+
+// SetNamespace sets namespace of points.
+func (s *Server) SetNamespace(namespace string) *Server {
+	s.namespace = namespace
+	return s
+}
+
+// AddTag adds tag for all points, overrides tag of metric with same key.
+func (s *Server) AddTag(key, value string) *Server {
+	if s.tags == nil {
+		s.tags = make(map[string]string)
+	}
+	s.tags[key] = value
+	return s
+}
+
+// SetFlushInterval sets interval of aggregating/writing metrics.
+func (s *Server) SetFlushInterval(interval time.Duration) *Server {
+	s.flushInterval = interval
+	return s
+}
+
+// SetGaugeExpiry sets expiry of gauge value of idle series, delta gauge after expiry starts from 0.
+func (s *Server) SetGaugeExpiry(expiry time.Duration) *Server {
+	s.gaugeExpiry = expiry
+	return s
+}
+
+// SetHistogramBuckets sets bucket upper bounds of timer/histogram/distribution,
+// panics if bounds are negative, NaN or not strictly increasing.
+func (s *Server) SetHistogramBuckets(bounds ...float64) *Server {
+	s.bounds = api.NewHistogramRecorder(bounds...).Bounds()
+	return s
+}
+
+// SetErrorHandler sets handler invoked when receiving invalid lines or failing to read.
+func (s *Server) SetErrorHandler(handler func(err error)) *Server {
+	s.errHandler = handler
+	return s
+}
+
+// Start starts listening/flushing in background, returns error if failing to listen.
+func (s *Server) Start() error {
+	if s.addr == "" && s.tcpAddr == "" {
+		return errors.New("statsd: no listen address")
+	}
+	if s.flushInterval <= 0 {
+		return errors.New("statsd: flush interval must be positive")
+	}
+	if s.gaugeExpiry <= 0 {
+		return errors.New("statsd: gauge expiry must be positive")
+	}
+	s.aggregator = newAggregator(s.bounds, s.gaugeExpiry)
+	if s.addr != "" {
+		conn, err := net.ListenPacket("udp", s.addr)
+		if err != nil {
+			return err
+		}
+		s.udpConn = conn
+	}
+	if s.tcpAddr != "" {
+		listener, err := net.Listen("tcp", s.tcpAddr)
+		if err != nil {
+			if s.udpConn != nil {
+				_ = s.udpConn.Close()
+			}
+			return err
+		}
+		s.listener = listener
+	}
+	if s.udpConn != nil {
+		s.wg.Add(1)
+		go s.readUDP()
+	}
+	if s.listener != nil {
+		s.wg.Add(1)
+		go s.acceptTCP()
+	}
+	s.wg.Add(1)
+	go s.run()
+	return nil
+}
+
+// Addr returns the address of UDP listener, nil if not listening.
+func (s *Server) Addr() net.Addr {
+	if s.udpConn == nil {
+		return nil
+	}
+	return s.udpConn.LocalAddr()
+}
+
+// TCPAddr returns the address of TCP listener, nil if not listening.
+func (s *Server) TCPAddr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Flush writes aggregated metrics through write client immediately.
+func (s *Server) Flush(ctx context.Context) {
+	if s.aggregator == nil {
+		return
+	}
+	for _, point := range s.aggregator.flush(nowFn()) {
+		point.SetNamespace(s.namespace)
+		for k, v := range s.tags {
+			point.AddTag(k, v)
+		}
+		s.write.AddPoint(ctx, point)
+	}
+}
+
+// Close stops listening, then flushes aggregated metrics, write client is not closed.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		if s.udpConn != nil {
+			_ = s.udpConn.Close()
+		}
+		s.mutex.Lock()
+		if s.listener != nil {
+			_ = s.listener.Close()
+		}
+		for conn := range s.conns {
+			_ = conn.Close()
+		}
+		s.mutex.Unlock()
+		s.wg.Wait()
+		s.Flush(context.TODO())
+	})
+}
+
+// run flushes aggregated metrics every interval until closed.
+func (s *Server) run() {
+	ticker := time.NewTicker(s.flushInterval)
+	defer func() {
+		ticker.Stop()
+		s.wg.Done()
+	}()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Flush(context.TODO())
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// readUDP reads packets until closed.
+func (s *Server) readUDP() {
+	defer s.wg.Done()
+
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, _, err := s.udpConn.ReadFrom(buf)
+		if n > 0 {
+			s.handle(buf[:n])
+		}
+		if err != nil {
+			if s.isClosed() {
+				return
+			}
+			s.emitErr(err)
+		}
+	}
+}
+
+// acceptTCP accepts connections until closed.
+func (s *Server) acceptTCP() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return
+			}
+			s.emitErr(err)
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return
+		}
+		s.mutex.Lock()
+		if s.isClosed() {
+			s.mutex.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mutex.Unlock()
+
+		s.wg.Add(1)
+		go s.readTCP(conn)
+	}
+}
+
+// readTCP reads newline separated lines of connection until EOF or closed.
+func (s *Server) readTCP(conn net.Conn) {
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		_ = conn.Close()
+		s.wg.Done()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 4096), maxPacketSize)
+	for scanner.Scan() {
+		s.handle(scanner.Bytes())
+	}
+	if err := scanner.Err(); err != nil && !s.isClosed() {
+		s.emitErr(err)
+	}
+}
+
+// handle parses packet, then aggregates metrics.
+func (s *Server) handle(packet []byte) {
+	if err := Parse(packet, s.aggregator.add); err != nil {
+		s.emitErr(err)
+	}
+}
+
+// isClosed checks if server is closed.
+func (s *Server) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// emitErr invokes error handler if set.
+func (s *Server) emitErr(err error) {
+	if s.errHandler != nil {
+		s.errHandler(err)
+	}
+}
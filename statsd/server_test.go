@@ -0,0 +1,109 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package statsd
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/api"
+	"github.com/lindb/client_go/internal/mock"
+)
+
+func TestServer_UDP(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	nowFn = func() time.Time { return now }
+	defer func() {
+		nowFn = time.Now
+	}()
+
+	write := mock.NewWrite()
+	var errs atomic.Int64
+	s := NewServer(write).SetAddr("127.0.0.1:0").SetNamespace("ns").AddTag("host", "h1").
+		SetFlushInterval(time.Hour).
+		SetErrorHandler(func(err error) {
+			errs.Add(1)
+		})
+	assert.NoError(t, s.Start())
+	assert.Nil(t, s.TCPAddr())
+
+	conn, err := net.Dial("udp", s.Addr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("requests:1|c|#host:h2,env:prod\ninvalid"))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return errs.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	s.Close()
+	s.Close()
+	points := write.Points()
+	assert.Len(t, points, 1)
+	assert.Equal(t, "requests", points[0].MetricName())
+	assert.Equal(t, "ns", points[0].Namespace())
+	assert.Equal(t, map[string]string{"host": "h1", "env": "prod"}, points[0].Tags())
+	assert.Equal(t, now, points[0].Timestamp())
+	assert.Equal(t, []api.Field{api.NewSum(countField, 1)}, points[0].Fields())
+}
+
+func TestServer_TCP(t *testing.T) {
+	write := mock.NewWrite()
+	s := NewServer(write).SetAddr("").SetTCPAddr("127.0.0.1:0").
+		SetFlushInterval(time.Hour).SetHistogramBuckets(10, 100)
+	assert.NoError(t, s.Start())
+	assert.Nil(t, s.Addr())
+
+	conn, err := net.Dial("tcp", s.TCPAddr().String())
+	assert.NoError(t, err)
+	_, err = conn.Write([]byte("latency:5|ms\nlatency:50|ms\n"))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		s.aggregator.mutex.Lock()
+		defer s.aggregator.mutex.Unlock()
+		series := s.aggregator.series[api.SeriesKey("", "latency", nil)]
+		return series != nil && series.histogram.Count() == 2
+	}, time.Second, time.Millisecond)
+	s.Flush(context.TODO())
+	assert.Len(t, write.Points(), 1)
+	assert.Equal(t, []api.Field{api.NewHistogram(5, 50, 55, 2, []float64{1, 1, 0}, s.bounds)},
+		write.Points()[0].Fields())
+
+	// connection still open while closing
+	s.Close()
+	_ = conn.Close()
+}
+
+func TestServer_Start_Error(t *testing.T) {
+	assert.Error(t, NewServer(mock.NewWrite()).SetAddr("").Start())
+	assert.Error(t, NewServer(mock.NewWrite()).SetFlushInterval(0).Start())
+	assert.Error(t, NewServer(mock.NewWrite()).SetGaugeExpiry(0).Start())
+	assert.Error(t, NewServer(mock.NewWrite()).SetAddr("invalid:addr:1").Start())
+	assert.Error(t, NewServer(mock.NewWrite()).SetAddr("127.0.0.1:0").SetTCPAddr("invalid:addr:1").Start())
+	assert.Panics(t, func() {
+		NewServer(mock.NewWrite()).SetHistogramBuckets(10, 1)
+	})
+
+	// close without start
+	NewServer(mock.NewWrite()).Close()
+}
@@ -0,0 +1,101 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package otel provides the OpenTelemetry adapter of trace.Tracer.
+package otel
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/lindb/client_go/trace"
+)
+
+const (
+	// instrumentationName represents the instrumentation library name.
+	instrumentationName = "github.com/lindb/client_go"
+)
+
+// tracer implements trace.Tracer interface based on OpenTelemetry.
+type tracer struct {
+	tracer     oteltrace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTracer creates a trace.Tracer with OpenTelemetry tracer provider and propagator,
+// uses global tracer provider if provider is nil, uses W3C trace context propagator if propagator is nil.
+func NewTracer(provider oteltrace.TracerProvider, propagator propagation.TextMapPropagator) trace.Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+	return &tracer{
+		tracer:     provider.Tracer(instrumentationName),
+		propagator: propagator,
+	}
+}
+
+// Start creates a client span, returns a copy of ctx carrying the span.
+func (t *tracer) Start(ctx context.Context, spanName string) (context.Context, trace.Span) {
+	ctx, s := t.tracer.Start(ctx, spanName, oteltrace.WithSpanKind(oteltrace.SpanKindClient))
+	return ctx, &span{span: s}
+}
+
+// Inject injects trace context(W3C traceparent etc.) carried by ctx into request headers.
+func (t *tracer) Inject(ctx context.Context, header http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// span implements trace.Span interface based on OpenTelemetry.
+type span struct {
+	span oteltrace.Span
+}
+
+// SetAttributes sets attributes of span.
+func (s *span) SetAttributes(attrs ...trace.Attribute) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch v := attr.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(attr.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(attr.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(attr.Key, v))
+		}
+	}
+	s.span.SetAttributes(kvs...)
+}
+
+// RecordError records error of operation, marks span failure.
+func (s *span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+// End completes the span.
+func (s *span) End() {
+	s.span.End()
+}
@@ -0,0 +1,64 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/lindb/client_go/trace"
+)
+
+func TestTracer(t *testing.T) {
+	assert.NotNil(t, NewTracer(nil, nil))
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := NewTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), nil)
+
+	ctx, span := tracer.Start(context.TODO(), trace.SpanWrite)
+	span.SetAttributes(
+		trace.String(trace.AttrDatabase, "db"),
+		trace.Int(trace.AttrPointCount, 10),
+		trace.Attribute{Key: "bool", Value: true},
+		trace.Attribute{Key: "ignore", Value: 1.0},
+	)
+	header := http.Header{}
+	tracer.Inject(ctx, header)
+	assert.NotEmpty(t, header.Get("traceparent"))
+	span.RecordError(fmt.Errorf("err"))
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, trace.SpanWrite, spans[0].Name())
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String(trace.AttrDatabase, "db"),
+		attribute.Int64(trace.AttrPointCount, 10),
+		attribute.Bool("bool", true),
+	}, spans[0].Attributes())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Len(t, spans[0].Events(), 1)
+}
@@ -0,0 +1,117 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package trace defines the tracing hooks for write/query requests,
+// see package otel for OpenTelemetry adapter.
+package trace
+
+import (
+	"context"
+	"net/http"
+)
+
+// Attribute keys recorded in span.
+const (
+	// AttrDatabase represents the database name.
+	AttrDatabase = "db.name"
+	// AttrStatement represents the query language(LinQL).
+	AttrStatement = "db.statement"
+	// AttrEndpoint represents the broker endpoint.
+	AttrEndpoint = "lindb.endpoint"
+	// AttrPointCount represents the number of points in write request.
+	AttrPointCount = "lindb.write.points"
+	// AttrPayloadBytes represents the payload size(bytes) of write request.
+	AttrPayloadBytes = "lindb.write.payload_bytes"
+	// AttrRetryAttempt represents the retry attempt of write request.
+	AttrRetryAttempt = "lindb.write.attempt"
+	// AttrStatusCode represents the HTTP response status code.
+	AttrStatusCode = "http.status_code"
+)
+
+// Span names.
+const (
+	SpanDataQuery     = "lindb.DataQuery"
+	SpanMetadataQuery = "lindb.MetadataQuery"
+	SpanWrite         = "lindb.Write"
+)
+
+// spanKey represents the context key of span.
+type spanKey struct{}
+
+// Tracer represents the tracer which creates spans around write/query requests.
+type Tracer interface {
+	// Start creates a span, returns a copy of ctx carrying the span.
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+	// Inject injects trace context(W3C traceparent etc.) carried by ctx into request headers.
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span represents a traced operation.
+type Span interface {
+	// SetAttributes sets attributes of span.
+	SetAttributes(attrs ...Attribute)
+	// RecordError records error of operation, marks span failure.
+	RecordError(err error)
+	// End completes the span.
+	End()
+}
+
+// Attribute represents the key/value attribute of span, value is string/int64/bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String creates a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int creates an int64 attribute.
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// StartSpan creates a span by tracer, returns a copy of ctx carrying the span,
+// returns a noop span if tracer is nil.
+func StartSpan(ctx context.Context, tracer Tracer, spanName string) (context.Context, Span) {
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := tracer.Start(ctx, spanName)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext returns the span carried by ctx, returns a noop span if not exist.
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// noopSpan implements Span interface, does nothing.
+type noopSpan struct{}
+
+// SetAttributes does nothing.
+func (noopSpan) SetAttributes(_ ...Attribute) {}
+
+// RecordError does nothing.
+func (noopSpan) RecordError(_ error) {}
+
+// End does nothing.
+func (noopSpan) End() {}
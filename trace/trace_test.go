@@ -0,0 +1,62 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSpan struct {
+	attrs []Attribute
+	err   error
+	ended bool
+}
+
+func (s *mockSpan) SetAttributes(attrs ...Attribute) { s.attrs = append(s.attrs, attrs...) }
+func (s *mockSpan) RecordError(err error)            { s.err = err }
+func (s *mockSpan) End()                             { s.ended = true }
+
+type mockTracer struct {
+	span *mockSpan
+}
+
+func (t *mockTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, t.span
+}
+func (t *mockTracer) Inject(_ context.Context, _ http.Header) {}
+
+func TestStartSpan(t *testing.T) {
+	ctx, span := StartSpan(context.TODO(), nil, SpanWrite)
+	assert.Equal(t, noopSpan{}, span)
+	assert.Equal(t, noopSpan{}, SpanFromContext(ctx))
+	span.SetAttributes(String(AttrDatabase, "db"))
+	span.RecordError(fmt.Errorf("err"))
+	span.End()
+
+	tracer := &mockTracer{span: &mockSpan{}}
+	ctx, span = StartSpan(context.TODO(), tracer, SpanWrite)
+	assert.Same(t, tracer.span, span)
+	assert.Same(t, tracer.span, SpanFromContext(ctx))
+	span.SetAttributes(String(AttrDatabase, "db"), Int(AttrPointCount, 10))
+	assert.Equal(t, []Attribute{{Key: AttrDatabase, Value: "db"}, {Key: AttrPointCount, Value: int64(10)}}, tracer.span.attrs)
+}
@@ -0,0 +1,112 @@
+// Licensed to LinDB under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. LinDB licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lindb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lindb/client_go/api"
+	"github.com/lindb/client_go/trace"
+)
+
+// recordTracer records spans, propagates span name as traceparent header.
+type recordTracer struct {
+	spans []*recordSpan
+	mutex sync.Mutex
+}
+
+// recordSpan records name/parent/attributes of span.
+type recordSpan struct {
+	name   string
+	parent string
+	attrs  []trace.Attribute
+}
+
+func (t *recordTracer) Start(ctx context.Context, spanName string) (context.Context, trace.Span) {
+	span := &recordSpan{name: spanName}
+	if parent, ok := trace.SpanFromContext(ctx).(*recordSpan); ok {
+		span.parent = parent.name
+	}
+	t.mutex.Lock()
+	t.spans = append(t.spans, span)
+	t.mutex.Unlock()
+	return ctx, span
+}
+
+func (t *recordTracer) Inject(ctx context.Context, header http.Header) {
+	if span, ok := trace.SpanFromContext(ctx).(*recordSpan); ok {
+		header.Set("traceparent", span.name)
+	}
+}
+
+func (s *recordSpan) SetAttributes(attrs ...trace.Attribute) {
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *recordSpan) RecordError(_ error) {}
+
+func (s *recordSpan) End() {}
+
+func TestTracing(t *testing.T) {
+	var traceParents []string
+	var mutex sync.Mutex
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		traceParents = append(traceParents, r.Header.Get("traceparent"))
+		mutex.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer svr.Close()
+
+	tracer := &recordTracer{}
+	cli := NewClientWithOptions(svr.URL, DefaultOptions().SetTracer(tracer))
+	ctx, cancel := context.WithCancel(context.TODO())
+	ctx, _ = trace.StartSpan(ctx, tracer, "caller")
+
+	_, err := cli.DataQuery().DataQuery(ctx, "test", "select load from cpu")
+	assert.NoError(t, err)
+	_, err = cli.DataQuery().MetadataQuery(ctx, "test", "show fields from cpu")
+	assert.NoError(t, err)
+	w := cli.Write("test")
+	for i := 0; i < 3; i++ {
+		w.AddPoint(ctx, api.NewPoint("cpu").AddField(api.NewSum("load", 1.0)))
+	}
+	// write request sent after caller's context canceled
+	cancel()
+	assert.NoError(t, cli.Close(context.TODO()))
+
+	spans := tracer.spans[1:]
+	assert.Len(t, spans, 3)
+	assert.Len(t, traceParents, 3)
+	for i, name := range []string{trace.SpanDataQuery, trace.SpanMetadataQuery, trace.SpanWrite} {
+		assert.Equal(t, name, spans[i].name)
+		// span is child of caller's span
+		assert.Equal(t, "caller", spans[i].parent)
+		assert.Equal(t, name, traceParents[i])
+		assert.Contains(t, spans[i].attrs, trace.String(trace.AttrDatabase, "test"))
+		assert.Contains(t, spans[i].attrs, trace.Int(trace.AttrStatusCode, http.StatusOK))
+	}
+	assert.Contains(t, spans[0].attrs, trace.String(trace.AttrStatement, "select load from cpu"))
+	assert.Contains(t, spans[2].attrs, trace.Int(trace.AttrPointCount, 3))
+}